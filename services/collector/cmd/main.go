@@ -2,8 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"log/slog"
 	"net/http"
 	"os"
@@ -13,8 +14,12 @@ import (
 
 	"github.com/jdbdev/go-cmc/config"
 	"github.com/jdbdev/go-cmc/db"
+	"github.com/jdbdev/go-cmc/internal/cmc"
+	"github.com/jdbdev/go-cmc/internal/coingecko"
 	"github.com/jdbdev/go-cmc/internal/coins"
+	"github.com/jdbdev/go-cmc/internal/credits"
 	"github.com/jdbdev/go-cmc/internal/mapper"
+	"github.com/jdbdev/go-cmc/internal/snapshot"
 	"github.com/jdbdev/go-cmc/internal/ticker"
 	"github.com/joho/godotenv"
 )
@@ -22,19 +27,29 @@ import (
 // Collector service (go-cmc)requires three services to run: internal/mapper, internal/coins and internal/ticker.
 // Mapper service generates an ID map based on coin lookups (symbols) using Coinmarketcap API for ID mapping.
 // ticker service fetches up to date data for each token/coin in the DB.
-// Services run concurrently at set intervals found in config/config.go file. Adjust based on API credit expenditure.
+// Services run concurrently at set intervals found in config/config.go file. The ticker interval is stretched
+// automatically (internal/credits) when the projected API credit spend would pass the configured budgets.
 // Services update the database with up to date data.
 // All configuration settings are stored in .env and loaded by config/config.go file.
 
-// Services holds the interfaces for the mapper, ticker and coins services.
+// Services holds the interfaces for the mapper, ticker and coins services, the credit accountant and the CMC client.
 type Services struct {
-	Mapper mapper.IDMapInterface
-	Ticker ticker.TickerInterface
-	Coins  coins.CoinInterface
+	Mapper  mapper.IDMapInterface
+	Ticker  ticker.TickerInterface
+	Coins   coins.CoinInterface
+	Credits *credits.Accountant
+	CMC     *cmc.Client
+	DB      *db.Database     // nil if the database is disabled
+	Store   ticker.TickStore // database, snapshot files (USE_DB=false) or nil
 }
 
 func main() {
 
+	// Command line flags and subcommands (operator tools, the collector runs normally without them)
+	writeFallbackMap := flag.Int("write-fallback-map", 0, "write the top N coins from the live CMC map to the embedded fallback map and exit")
+	fallbackMapOut := flag.String("fallback-map-out", mapper.FallbackMapPath, "output file of -write-fallback-map")
+	flag.Parse()
+
 	//==========================================================================
 	// Configuration & Initialization/Setup
 	//==========================================================================
@@ -45,10 +60,35 @@ func main() {
 	slog.Info("CMC API application starting - Version 0.1")
 	// Initialize applicaiton configuration
 	app := InitConfig(logger)
+
+	// Migrate subcommand (cmd/migrate.go) runs and exits
+	if flag.Arg(0) == "migrate" {
+		if err := RunMigrate(app, logger, flag.Args()[1:]); err != nil {
+			logger.Error("migrate failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// Replay subcommand (cmd/replay.go) writes snapshot files to the database and exits
+	if flag.Arg(0) == "replay" {
+		if err := RunReplay(app, logger, flag.Args()[1:]); err != nil {
+			logger.Error("replay failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize http client
 	client := &http.Client{}
-	// Initialize services Mapper, Ticker and Coins. Inject dependencies required.
-	services := InitServices(app, logger, client)
+
+	// Regenerate embedded fallback map snapshot (internal/mapper/fallback_map.json) and exit. Needs no database.
+	if *writeFallbackMap > 0 {
+		if err := WriteFallbackMap(app, InitServices(app, logger, client, nil), *writeFallbackMap, *fallbackMapOut); err != nil {
+			logger.Error("failed to write fallback map", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	//==========================================================================
 	// Database Setup
@@ -61,9 +101,17 @@ func main() {
 	}
 	if database != nil {
 		defer database.Close()
-		logger.Info("Database connection successful")
+		if database.Health().Degraded {
+			logger.Warn("Database unreachable - starting degraded, ticks are buffered until it reconnects")
+		} else {
+			logger.Info("Database connection successful")
+		}
 	}
 
+	// Initialize services Mapper, Ticker and Coins. Inject dependencies required (repositories on database).
+	services := InitServices(app, logger, client, database)
+	services.Store = InitStore(app, logger, services)
+
 	//==========================================================================
 	// Service Calls
 	//==========================================================================
@@ -74,19 +122,49 @@ func main() {
 
 	initialCoins, err := services.Mapper.GetCMCTopCoins(ctx, 5)
 	if err != nil {
-		logger.Error("Failed getting topcoins", "error", err)
+		logger.Error("Failed getting topcoins - using embedded fallback map", "error", err)
+		if initialCoins, err = mapper.FallbackTopCoins(5); err != nil {
+			logger.Error("Failed getting fallback topcoins", "error", err)
+		}
 	} else {
-		logger.Info("Initial top coins: %s", "data", string(initialCoins)) // convert []byte to string for testing only
+		logger.Info("Initial top coins", "count", len(initialCoins))
+	}
+
+	// coinService calls with context timeout. Seeding is idempotent and safe on every startup.
+	// Without a database the tracked coins are kept in memory.
+	SeedCoins(ctx, logger, services, initialCoins)
+
+	// Restore credit usage totals for today and this month
+	if err := services.Credits.Load(ctx); err != nil {
+		logger.Error("Failed loading credit usage", "error", err)
 	}
 
 	// tickerService calls with context timeout
-	// coinService calls with context timeout
 
 	//==========================================================================
 	// Go Routines
 	//==========================================================================
 
 	go updateCoinQuotes(app, logger, services)
+	if app.AppCfg.UseDB {
+		go syncIDMap(app, logger, services)
+	}
+
+	// Database health pings: degraded flag on /status, reconnect with backoff and flush the buffered ticks
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	if database != nil {
+		go database.Monitor(monitorCtx, app.DB.HealthInterval, onDatabaseRecovered(app, logger, services, database, initialCoins))
+	}
+
+	// Status endpoint (GET /status)
+	srv := InitServer(app, services)
+	go func() {
+		logger.Info("Status server starting", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("status server failed", "error", err)
+		}
+	}()
 
 	//==========================================================================
 	// Application Shutdown (blocks main() thread until shutdown)
@@ -98,6 +176,11 @@ func main() {
 	<-quit
 
 	fmt.Println("Shutting down gracefully...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("status server shutdown failed", "error", err)
+	}
 }
 
 // InitConfig initializes the application configuration and prints to stdout basic information
@@ -111,55 +194,233 @@ func InitConfig(logger *slog.Logger) *config.AppConfig {
 	return app
 }
 
-// InitServices initializes the internal services Mapper, Ticker and Coins.
-func InitServices(app *config.AppConfig, logger *slog.Logger, client *http.Client) *Services {
-	mapperService := mapper.NewIDMapService(app, logger, client)
-	coinService := coins.NewCoinService(logger)
-	tickerService := ticker.NewTickerService(app, coinService, logger)
+// InitServices initializes the internal services Mapper, Ticker and Coins. The repositories are built on database,
+// a nil database (DB disabled) makes them return db.ErrNotConnected, except tracked coins which are kept in memory.
+func InitServices(app *config.AppConfig, logger *slog.Logger, client *http.Client, database *db.Database) *Services {
+	var trackedCoins coins.TrackedCoinRepo = coins.NewPostgresTrackedCoinRepo(database)
+	if database == nil {
+		trackedCoins = coins.NewMemoryTrackedCoinRepo()
+	}
+	accountant := credits.NewAccountant(app, credits.NewPostgresUsageRepo(database), logger)
+	cmcClient := cmc.NewClient(app, client, accountant, logger) // shared by mapper and ticker
+	mapperService := mapper.NewIDMapService(app, logger, cmcClient, mapper.NewPostgresIDMapRepo(database))
+	coinService := coins.NewCoinService(logger, mapperService, trackedCoins)
+	tickerService := ticker.NewTickerService(app,
+		ticker.NewPostgresCoinInfoRepo(database),
+		ticker.NewPostgresQuoteRepo(database),
+		coinService, cmcClient,
+		InitSecondaryProvider(app, logger, client, database),
+		logger)
 
 	return &Services{
-		Mapper: mapperService,
-		Ticker: tickerService,
-		Coins:  coinService,
+		Mapper:  mapperService,
+		Ticker:  tickerService,
+		Coins:   coinService,
+		Credits: accountant,
+		CMC:     cmcClient,
+		DB:      database,
 	}
 }
 
-// InitDatabase initializes the database instance if enabled in settings
+// InitStore returns where ticks are stored: the database, snapshot files if the database is disabled
+// (internal/snapshot), nil if neither is enabled.
+func InitStore(app *config.AppConfig, logger *slog.Logger, services *Services) ticker.TickStore {
+	if app.AppCfg.UseDB {
+		return services.Ticker
+	}
+	if !app.Snapshot.Enabled {
+		logger.Warn("Database and snapshots disabled - ticks are not stored")
+		return nil
+	}
+	store, err := snapshot.NewFileStore(app, logger)
+	if err != nil {
+		logger.Error("Failed initializing snapshot files - ticks are not stored", "error", err)
+		return nil
+	}
+	return store
+}
+
+// InitSecondaryProvider returns the secondary price provider set in settings, nil if none.
+func InitSecondaryProvider(app *config.AppConfig, logger *slog.Logger, client *http.Client, database *db.Database) ticker.PriceProvider {
+	switch app.Providers.Secondary {
+	case "":
+		return nil
+	case coingecko.ProviderName:
+		return coingecko.NewCoinGeckoService(app, coingecko.NewDBIDMap(database), client, logger) // CoinGecko IDs from provider_id_map
+	default:
+		logger.Warn("Unknown secondary price provider - using CMC only", "provider", app.Providers.Secondary)
+		return nil
+	}
+}
+
+// InitDatabase initializes the database instance if enabled in settings. If Postgres doesn't answer the database
+// is returned degraded and reconnected in the background (Database.Monitor), only a bad configuration is an error.
 func InitDatabase(app *config.AppConfig, logger *slog.Logger) (*db.Database, error) {
 	if !app.AppCfg.UseDB {
 		logger.Info("Database disabled in settings - not in use")
 		return nil, nil
 	}
-	// Create new Database instance in db/postgres.go
-	database, err := db.NewDatabase(app)
+	// Create new Database instance in db/postgres.go (retries until Postgres answers, DB_CONNECT_RETRIES)
+	database, err := db.NewDatabase(context.Background(), app, logger)
 	if err != nil {
-		log.Fatal(err)
+		logger.Error("Failed connecting to database", "error", err)
+		// Migrations run once the database reconnects (onDatabaseRecovered)
+		return db.OpenDatabase(app, logger)
+	}
+
+	// Apply pending migrations (db/migrations) before services use the tables
+	if app.DB.AutoMigrate {
+		if err := MigrateDatabase(logger, database); err != nil {
+			return database, err
+		}
 	}
-	db.SetDatabase(database)
 	return database, nil
 }
 
+// SeedCoins checks the tracked_coins table exists and adds the initial coins to it.
+func SeedCoins(ctx context.Context, logger *slog.Logger, services *Services, initialCoins []mapper.CmcCoinID) {
+	if err := services.Coins.InitializeCoinTable(ctx); err != nil {
+		logger.Error("Failed initializing coin table", "error", err)
+	} else if len(initialCoins) > 0 {
+		if _, err := services.Coins.SeedTrackedCoins(ctx, initialCoins); err != nil {
+			logger.Error("Failed seeding tracked coins", "error", err)
+		}
+	}
+}
+
+// onDatabaseRecovered returns the function run by Database.Monitor when the database answers again: pending
+// migrations and seeding (skipped if the database was down at startup), then the ticks buffered during the outage.
+func onDatabaseRecovered(app *config.AppConfig, logger *slog.Logger, services *Services, database *db.Database,
+	initialCoins []mapper.CmcCoinID) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		if app.DB.AutoMigrate {
+			if err := MigrateDatabase(logger, database); err != nil {
+				logger.Error("failed to migrate database", "error", err)
+			}
+		}
+		ctx, cancel := context.WithTimeout(ctx, app.CMC.RequestTimeout)
+		defer cancel()
+		SeedCoins(ctx, logger, services, initialCoins)
+
+		// Credit usage wasn't loaded if the collector started during the outage
+		if err := services.Credits.Load(ctx); err != nil {
+			logger.Error("Failed loading credit usage", "error", err)
+		}
+
+		if _, err := services.Ticker.FlushBuffer(ctx); err != nil {
+			logger.Error("failed to flush buffered ticks", "error", err, "buffer", services.Ticker.BufferStatus())
+		}
+	}
+}
+
 // updateCoinQuotes orchestrates calls to the API and DB updates with new data on set time interval.
+// The interval is adjusted after every tick to stay within the API credit budgets.
 func updateCoinQuotes(app *config.AppConfig, logger *slog.Logger, services *Services) {
 	timeInterval := app.Interval.TickerInterval
 	ticker := time.NewTicker(timeInterval) // returns a *time.Ticker channel that reads from the channel C at set interval
 	defer ticker.Stop()                    // stop ticker at function exit
+	tickCredits := 0                       // credits used by the last successful tick
+
+	// Start from the estimated tick cost (coins, batches and convert currencies) so the first
+	// interval already respects the credit budgets
+	ctx, cancel := context.WithTimeout(context.Background(), app.CMC.RequestTimeout)
+	if ids, err := services.Coins.GetTrackedCoinIDs(ctx); err == nil {
+		tickCredits = services.Ticker.EstimateTickCredits(len(ids))
+		logger.Info("Estimated API credits per tick", "coins", len(ids), "currencies", len(app.CMC.Convert), "credits", tickCredits)
+		if next := services.Credits.NextInterval(app.Interval.TickerInterval, tickCredits); next != timeInterval {
+			timeInterval = next
+			ticker.Reset(timeInterval)
+		}
+	}
+	cancel()
+
 	for range ticker.C {
-		// Create new context with timeout for each iteration of API call
+		// Create new context with timeout for each iteration of API call. Storing the tick gets its own
+		// timeout (saveTick) so a slow fetch doesn't leave the write without time.
 		ctx, cancel := context.WithTimeout(context.Background(), app.CMC.RequestTimeout)
 
 		// Call API (fetchAndDecodeData() in internal/ticker/service.go).
+		// A partial response is returned if only some quote batches failed.
 		cmcResponse, err := services.Ticker.FetchAndDecodeData(ctx)
-		if err != nil {
+		cancel()
+		if errors.Is(err, cmc.ErrCircuitOpen) {
+			// Breaker state changes are logged once by internal/cmc, not on every tick
+			logger.Debug("skipped tick - CMC circuit breaker open", "error", err)
+		} else if err != nil {
 			logger.Error("failed to fetch and decode data", "error", err)
-			cancel()
-			continue
 		}
-		cancel()
 
-		// DEBUGGING ONLY REMOVE BEFORE PRODUCTION.
-		logger.Info("Response body unmarshalled intoCMCResponse: %+v\n", "data", cmcResponse.Data)
+		if cmcResponse != nil {
+			tickCredits = cmcResponse.Status.CreditCount
+
+			// Save data to DB (UpdateDB() in internal/ticker/service.go) or snapshot files.
+			if services.Store != nil {
+				saveTick(app, logger, services, cmcResponse)
+			}
+		}
+
+		// Stretch (or restore) the interval based on the credit budgets
+		if next := services.Credits.NextInterval(app.Interval.TickerInterval, tickCredits); next != timeInterval {
+			timeInterval = next
+			ticker.Reset(timeInterval)
+		}
+		burn := services.Credits.BurnRate()
+		logger.Info("API credit burn rate",
+			"last_hour", burn.LastHour,
+			"used_today", burn.UsedToday,
+			"used_month", burn.UsedMonth,
+			"projected_daily", burn.ProjectedDaily,
+			"projected_monthly", burn.ProjectedMonthly,
+			"interval", timeInterval)
+	}
+}
+
+// saveTick stores a tick with its own write timeout (DB_WRITE_TIMEOUT). During a database outage the tick is
+// buffered by the ticker service.
+func saveTick(app *config.AppConfig, logger *slog.Logger, services *Services, cmcResponse *ticker.CMCResponse) {
+	ctx, cancel := context.WithTimeout(context.Background(), app.DB.WriteTimeout)
+	defer cancel()
 
+	err := services.Store.StoreTick(ctx, cmcResponse)
+	if errors.Is(err, ticker.ErrWriteBuffered) {
+		buffer := services.Ticker.BufferStatus()
+		logger.Warn("database unreachable - tick buffered",
+			"buffered_ticks", buffer.Ticks, "buffered_quotes", buffer.Quotes, "dropped_ticks", buffer.Dropped, "error", err)
+	} else if err != nil {
+		logger.Error("failed to store tick", "error", err)
+	}
+}
+
+// WriteFallbackMap fetches the top coins from the live CMC map and writes them as the fallback map snapshot to path.
+func WriteFallbackMap(app *config.AppConfig, services *Services, size int, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.CMC.RequestTimeout)
+	defer cancel()
+
+	topCoins, err := services.Mapper.GetCMCTopCoins(ctx, size)
+	if err != nil {
+		return err
+	}
+	if err := mapper.WriteFallbackMap(topCoins, path); err != nil {
+		return err
+	}
+	slog.Info("Fallback map written", "file", path, "count", len(topCoins))
+	return nil
+}
+
+// syncIDMap keeps the local id_map table in sync with CMC. Runs once at startup then on MapperInterval.
+func syncIDMap(app *config.AppConfig, logger *slog.Logger, services *Services) {
+	ticker := time.NewTicker(app.Interval.MapperInterval)
+	defer ticker.Stop()
+	for {
+		// Full sync pages through the whole map, use sync timeout instead of request timeout
+		ctx, cancel := context.WithTimeout(context.Background(), app.Interval.MapperSyncTimeout)
+		if _, err := services.Mapper.SyncIDMap(ctx); errors.Is(err, cmc.ErrCircuitOpen) {
+			logger.Warn("skipped ID map sync - CMC circuit breaker open", "error", err)
+		} else if err != nil {
+			logger.Error("failed to sync ID map", "error", err)
+		}
+		cancel()
+		<-ticker.C
 	}
 }
 
@@ -167,6 +428,7 @@ func updateCoinQuotes(app *config.AppConfig, logger *slog.Logger, services *Serv
 func PrintSettings(app *config.AppConfig) {
 	fmt.Printf("App in production: %v\n", app.AppCfg.InProduciton)
 	fmt.Printf("Use DB: %v\n", app.AppCfg.UseDB)
+	fmt.Printf("Ticker mode: %v\n", app.CMC.TickerMode)
 	fmt.Printf("Base URL: %v\n", app.CMC.BaseURL)
 	fmt.Printf("Request Timeout: %v\n", app.CMC.RequestTimeout)
 }
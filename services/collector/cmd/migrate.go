@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// Migrate subcommand for operators, runs against the configured database even if USE_DB=false:
+//
+//	go run ./cmd migrate up       apply all pending migrations
+//	go run ./cmd migrate down [N] revert the last N migrations (default 1)
+//	go run ./cmd migrate status   list migrations and whether they are applied
+//	go run ./cmd migrate to N     migrate up or down to version N (0 reverts everything)
+//	go run ./cmd migrate baseline N  record versions up to N as applied without running them (schema applied by hand)
+
+// migrateTimeout bounds a migrate subcommand run (includes waiting for another instance holding the lock)
+const migrateTimeout = 10 * time.Minute
+
+// RunMigrate runs the migrate subcommand with its arguments (after "migrate").
+func RunMigrate(app *config.AppConfig, logger *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [N]|status|to N|baseline N")
+	}
+
+	database, err := db.NewDatabase(context.Background(), app, logger)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	migrator, err := db.NewMigrator(database, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		n, err := migrator.Up(ctx)
+		logger.Info("Migrations applied", "count", n)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+		}
+		n, err := migrator.Down(ctx, steps)
+		logger.Info("Migrations reverted", "count", n)
+		return err
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate to N")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid migration version %q", args[1])
+		}
+		n, err := migrator.To(ctx, version)
+		logger.Info("Migrated", "version", version, "count", n)
+		return err
+	case "baseline":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate baseline N")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid migration version %q", args[1])
+		}
+		n, err := migrator.Baseline(ctx, version)
+		logger.Info("Migrations recorded as applied", "version", version, "count", n)
+		return err
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q (expected up, down, status, to or baseline)", args[0])
+	}
+}
+
+// MigrateDatabase applies pending migrations at startup (DB_AUTO_MIGRATE).
+func MigrateDatabase(logger *slog.Logger, database *db.Database) error {
+	migrator, err := db.NewMigrator(database, logger)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	n, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	logger.Info("Database schema up to date", "version", migrator.Latest(), "applied", n)
+	return nil
+}
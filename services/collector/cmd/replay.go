@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/db"
+	"github.com/jdbdev/go-cmc/internal/snapshot"
+	"github.com/jdbdev/go-cmc/internal/ticker"
+)
+
+// Replay subcommand for operators, writes the snapshot files (internal/snapshot) of a run with USE_DB=false to the
+// configured database. Quotes older than the stored latest quote only add history rows.
+//
+//	go run ./cmd replay [-from 2026-01-02T00:00:00Z] [-to 2026-01-03T00:00:00Z] [DIR]  (default SNAPSHOT_DIR)
+
+// RunReplay runs the replay subcommand with its arguments (after "replay").
+func RunReplay(app *config.AppConfig, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	from := flags.String("from", "", "replay snapshots fetched at or after this time (RFC 3339)")
+	to := flags.String("to", "", "replay snapshots fetched before this time (RFC 3339)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	var opts snapshot.ReplayOptions
+	var err error
+	if *from != "" {
+		if opts.From, err = time.Parse(time.RFC3339, *from); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if *to != "" {
+		if opts.To, err = time.Parse(time.RFC3339, *to); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+	dir := app.Snapshot.Dir
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	// Stop between ticks on interrupt, every tick is written in its own transaction
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDatabase(ctx, app, logger)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	if app.DB.AutoMigrate {
+		if err := MigrateDatabase(logger, database); err != nil {
+			return err
+		}
+	}
+
+	tickerService := ticker.NewTickerService(app,
+		ticker.NewPostgresCoinInfoRepo(database),
+		ticker.NewPostgresQuoteRepo(database),
+		nil, nil, nil, logger) // no fetching, no tracked coins or CMC client needed
+	result, err := snapshot.Replay(ctx, dir, opts, tickerService.ReplayTick)
+	logger.Info("Snapshots replayed", "dir", dir, "files", result.Files, "ticks", result.Ticks, "skipped_lines", result.Skipped)
+	if err != nil {
+		return err
+	}
+	if result.Files == 0 {
+		logger.Warn("No snapshot files found", "dir", dir)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/db"
+	"github.com/jdbdev/go-cmc/internal/cmc"
+	"github.com/jdbdev/go-cmc/internal/credits"
+	"github.com/jdbdev/go-cmc/internal/ticker"
+)
+
+// Status endpoint for operators and health checks. GET /status returns the CMC circuit breaker state,
+// the API credit burn rate and, if the database is enabled, its health and write buffer as JSON.
+
+// StatusResponse holds the JSON body of GET /status.
+type StatusResponse struct {
+	Time    time.Time         `json:"time"`
+	CMC     cmc.BreakerStatus `json:"cmc"`
+	Credits credits.BurnRate  `json:"credits"`
+	DB      *DBStatus         `json:"db,omitempty"` // nil if the database is disabled
+}
+
+// DBStatus holds the database health and the ticks buffered while it is degraded.
+type DBStatus struct {
+	db.Health
+	Buffer ticker.BufferStatus `json:"buffer"`
+}
+
+// InitServer creates the status HTTP server and stores it in the app configuration (app.Srv).
+func InitServer(app *config.AppConfig, services *Services) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", statusHandler(app, services))
+
+	app.Srv = &http.Server{
+		Addr:         app.AppCfg.StatusAddr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	return app.Srv
+}
+
+// statusHandler writes the current StatusResponse.
+func statusHandler(app *config.AppConfig, services *Services) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := StatusResponse{
+			Time:    time.Now().UTC(),
+			CMC:     services.CMC.BreakerStatus(),
+			Credits: services.Credits.BurnRate(),
+		}
+		if app.AppCfg.UseDB {
+			status.DB = &DBStatus{Health: services.DB.Health(), Buffer: services.Ticker.BufferStatus()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			slog.Error("failed to write status response", "error", err)
+		}
+	}
+}
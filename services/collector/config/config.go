@@ -3,22 +3,28 @@ package config
 import (
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // AppConfig holds all configuration settings for the application
 type AppConfig struct {
-	DB       DBSettings
-	CMC      CMCSettings
-	AppCfg   AppSettings
-	Srv      *http.Server
-	Interval IntervalSettings
+	DB        DBSettings
+	CMC       CMCSettings
+	CoinGecko CoinGeckoSettings
+	Providers ProviderSettings
+	AppCfg    AppSettings
+	Srv       *http.Server
+	Interval  IntervalSettings
+	Snapshot  SnapshotSettings
 }
 
 // AppCofig holds general application settings
 type AppSettings struct {
 	InProduciton bool
 	UseDB        bool
+	StatusAddr   string // listen address of the status endpoint (GET /status)
 }
 
 // DBConfig holds database configuration settings
@@ -28,6 +34,24 @@ type DBSettings struct {
 	User     string
 	Password string
 	DBName   string
+
+	URL         string // full connection URL (DATABASE_URL), overrides the settings above and the TLS settings
+	SSLMode     string // "disable", "require", "verify-ca" or "verify-full"
+	SSLRootCert string // CA certificate file used to verify the server (verify-ca, verify-full)
+
+	MaxOpenConns    int           // max open connections, 0 = unlimited
+	MaxIdleConns    int           // max idle connections kept in the pool
+	ConnMaxLifetime time.Duration // connections are closed and reopened after this time, 0 = never
+
+	ConnectTimeout    time.Duration // timeout of each connection attempt and startup ping
+	ConnectRetries    int           // startup ping retries while the database is unreachable
+	ConnectRetryDelay time.Duration // first retry delay, doubled per attempt (max 30s)
+
+	WriteTimeout    time.Duration // timeout of a tick write, separate from the CMC request timeout
+	HealthInterval  time.Duration // time between health pings of the database
+	BufferMaxQuotes int           // quotes kept in memory while the database is unreachable, 0 = drop ticks
+
+	AutoMigrate bool // apply pending db/migrations at startup
 }
 
 // CMCCOnfig holds Coinmarketcap API configuration
@@ -36,13 +60,59 @@ type CMCSettings struct {
 	BaseURL        string
 	QuotesURL      string
 	IDMapURL       string
+	IDMapPageSize  int      // entries per /map page during ID map sync (CMC max 5000)
+	QuotesBatch    int      // CMC IDs per quotes request
+	QuotesWorkers  int      // concurrent quotes requests per tick
+	Convert        []string // quote currencies (ex. USD, CAD, EUR, BTC), each one past the first costs 1 credit per call
 	RequestTimeout time.Duration
+	MaxRetries     int           // retries for transient errors (429, 5xx, network)
+	RetryBaseDelay time.Duration // first retry delay, doubled per attempt with jitter
+	RetryMaxDelay  time.Duration // max retry delay (Retry-After header overrides)
+
+	TickerMode       string // "quotes" (tracked_coins, QuotesURL) or "listings" (top N listings, BaseURL)
+	ListingsLimit    int    // listings mode: number of top coins fetched per tick
+	ListingsPageSize int    // listings mode: coins per listings request (CMC max 5000)
+	ListingsSort     string // listings mode: sort field (ex. market_cap, volume_24h, percent_change_24h)
+	ListingsSortDir  string // listings mode: "asc" or "desc"
+	AutoTrackTopN    int    // listings mode: add coins ranked in the top N to tracked_coins, 0 = disabled
+
+	BreakerThreshold int           // consecutive failed calls that open the circuit breaker, 0 = disabled
+	BreakerCoolDown  time.Duration // time the breaker stays open before a half-open probe
+
+	DailyCreditBudget   int // max API credits per UTC day, 0 = unlimited
+	MonthlyCreditBudget int // max API credits per UTC month, 0 = unlimited
+}
+
+// CoinGeckoSettings holds CoinGecko API configuration (secondary price provider)
+type CoinGeckoSettings struct {
+	APIKey         string // demo or pro API key, pro key is used when BaseURL is the pro API
+	BaseURL        string
+	QuotesEndpoint string // "markets" (/coins/markets) or "simple" (/simple/price)
+	RequestTimeout time.Duration
+}
+
+// ProviderSettings holds the price provider failover and reconciliation settings.
+// Coinmarketcap is always the primary provider.
+type ProviderSettings struct {
+	Secondary         string  // secondary price provider ("coingecko"), empty = CMC only
+	ReconcileMode     string  // "failover", "median" or "primary"
+	Tolerance         float64 // primary mode: max relative deviation to keep the CMC price (0.01 = 1%)
+	DisagreeThreshold float64 // relative deviation above which a coin is flagged, 0 = never
+}
+
+// SnapshotSettings holds the file storage settings used when the database is disabled (USE_DB=false)
+type SnapshotSettings struct {
+	Enabled   bool          // write every tick to NDJSON snapshot files when USE_DB=false
+	Dir       string        // snapshot directory
+	Rotate    time.Duration // a new file is started every Rotate (UTC aligned, ex. 1h or 24h)
+	Retention time.Duration // files older than this are deleted, 0 = keep all
 }
 
 // IntervalSettings holds the time settings in seconds for the ticker and mapper services
 type IntervalSettings struct {
-	TickerInterval time.Duration
-	MapperInterval time.Duration
+	TickerInterval    time.Duration
+	MapperInterval    time.Duration
+	MapperSyncTimeout time.Duration // timeout for a full ID map sync (all pages)
 }
 
 // NewConfig creates and returns a new AppConfig instance
@@ -54,23 +124,84 @@ func NewAppConfig() *AppConfig {
 			User:     getEnv("DB_USER", "postgres"),
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "postgres"),
+
+			URL:         getEnv("DATABASE_URL", ""),
+			SSLMode:     getEnv("DB_SSLMODE", "disable"),
+			SSLRootCert: getEnv("DB_SSLROOTCERT", ""),
+
+			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 10),
+			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", "30m"),
+
+			ConnectTimeout:    getEnvAsDuration("DB_CONNECT_TIMEOUT", "5s"),
+			ConnectRetries:    getEnvAsInt("DB_CONNECT_RETRIES", 5),
+			ConnectRetryDelay: getEnvAsDuration("DB_CONNECT_RETRY_DELAY", "1s"),
+
+			WriteTimeout:    getEnvAsDuration("DB_WRITE_TIMEOUT", "30s"),
+			HealthInterval:  getEnvAsDuration("DB_HEALTH_INTERVAL", "15s"),
+			BufferMaxQuotes: getEnvAsInt("DB_BUFFER_MAX_QUOTES", 50000),
+
+			AutoMigrate: getEnv("DB_AUTO_MIGRATE", "true") == "true",
 		},
 		CMC: CMCSettings{
 			APIKey:         getEnv("CMC_API_KEY", "123"),
 			BaseURL:        getEnv("CMC_BASE_URL", ""),
 			QuotesURL:      getEnv("CMC_QUOTES_URL", ""),
 			IDMapURL:       getEnv("CMC_ID_MAP_URL", ""),
+			IDMapPageSize:  getEnvAsInt("CMC_ID_MAP_PAGE_SIZE", 5000),
+			QuotesBatch:    getEnvAsInt("CMC_QUOTES_BATCH_SIZE", 100),
+			QuotesWorkers:  getEnvAsInt("CMC_QUOTES_CONCURRENCY", 2),
+			Convert:        getEnvAsList("CMC_CONVERT", "USD"),
 			RequestTimeout: getEnvAsDuration("CMC_REQUEST_TIMEOUT", "30s"),
+			MaxRetries:     getEnvAsInt("CMC_MAX_RETRIES", 3),
+			RetryBaseDelay: getEnvAsDuration("CMC_RETRY_BASE_DELAY", "1s"),
+			RetryMaxDelay:  getEnvAsDuration("CMC_RETRY_MAX_DELAY", "20s"),
+
+			TickerMode:       getEnv("TICKER_MODE", "quotes"),
+			ListingsLimit:    getEnvAsInt("CMC_LISTINGS_LIMIT", 200),
+			ListingsPageSize: getEnvAsInt("CMC_LISTINGS_PAGE_SIZE", 5000),
+			ListingsSort:     getEnv("CMC_LISTINGS_SORT", "market_cap"),
+			ListingsSortDir:  getEnv("CMC_LISTINGS_SORT_DIR", "desc"),
+			AutoTrackTopN:    getEnvAsInt("AUTO_TRACK_TOP_N", 0),
+
+			BreakerThreshold: getEnvAsInt("CMC_BREAKER_THRESHOLD", 5),
+			BreakerCoolDown:  getEnvAsDuration("CMC_BREAKER_COOLDOWN", "5m"),
+
+			DailyCreditBudget:   getEnvAsInt("CMC_DAILY_CREDIT_BUDGET", 0),
+			MonthlyCreditBudget: getEnvAsInt("CMC_MONTHLY_CREDIT_BUDGET", 0),
+		},
+
+		CoinGecko: CoinGeckoSettings{
+			APIKey:         getEnv("COINGECKO_API_KEY", ""),
+			BaseURL:        getEnv("COINGECKO_BASE_URL", "https://api.coingecko.com/api/v3"),
+			QuotesEndpoint: getEnv("COINGECKO_QUOTES_ENDPOINT", "markets"),
+			RequestTimeout: getEnvAsDuration("COINGECKO_REQUEST_TIMEOUT", "30s"),
+		},
+
+		Providers: ProviderSettings{
+			Secondary:         getEnv("PROVIDER_SECONDARY", ""),
+			ReconcileMode:     getEnv("RECONCILE_MODE", "failover"),
+			Tolerance:         getEnvAsFloat("RECONCILE_TOLERANCE", 0.01),
+			DisagreeThreshold: getEnvAsFloat("DISAGREE_THRESHOLD", 0.05),
 		},
 
 		AppCfg: AppSettings{
 			InProduciton: getEnv("IN_PRODUCTION", "false") == "true",
 			UseDB:        getEnv("USE_DB", "false") == "true",
+			StatusAddr:   getEnv("STATUS_ADDR", ":8080"),
 		},
 
 		Interval: IntervalSettings{
-			TickerInterval: getEnvAsDuration("TICKER_INTERVAL", "2m"),
-			MapperInterval: getEnvAsDuration("MAPPER_INTERVAL", "24h"),
+			TickerInterval:    getEnvAsDuration("TICKER_INTERVAL", "2m"),
+			MapperInterval:    getEnvAsDuration("MAPPER_INTERVAL", "24h"),
+			MapperSyncTimeout: getEnvAsDuration("MAPPER_SYNC_TIMEOUT", "10m"),
+		},
+
+		Snapshot: SnapshotSettings{
+			Enabled:   getEnv("SNAPSHOT_ENABLED", "true") == "true",
+			Dir:       getEnv("SNAPSHOT_DIR", "./snapshots"),
+			Rotate:    getEnvAsDuration("SNAPSHOT_ROTATE", "1h"),
+			Retention: getEnvAsDuration("SNAPSHOT_RETENTION", "720h"),
 		},
 	}
 }
@@ -94,3 +225,34 @@ func getEnvAsDuration(key, defaultValue string) time.Duration {
 	}
 	return duration
 }
+
+// getEnvAsInt() function to get env variables as int from .env file
+func getEnvAsInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		// If parsing fails or not set, return default
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsFloat() function to get env variables as float from .env file
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(getEnv(key, ""), 64)
+	if err != nil {
+		// If parsing fails or not set, return default
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsList() function to get comma separated env variables as a list (upper case, blanks removed) from .env file
+func getEnvAsList(key, defaultValue string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.ToUpper(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
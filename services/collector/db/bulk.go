@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Bulk write path for ticks with thousands of rows. Rows are written with multi-row
+// INSERT ... VALUES (...), (...) ON CONFLICT statements, BatchSize rows per statement, instead of one
+// statement per row. Each batch runs under a savepoint: if Postgres rejects a batch because of a row's data
+// (out of range value, constraint violation, the same key twice in one batch) the batch is rolled back to the
+// savepoint and written again one row at a time so only the bad rows are rejected and reported.
+
+// DefaultBulkBatchSize is the number of rows per INSERT statement when BulkUpsert.BatchSize is not set.
+const DefaultBulkBatchSize = 500
+
+// maxBindParams is the Postgres limit of bind parameters ($1..$65535) per statement.
+const maxBindParams = 65535
+
+// BulkUpsert describes a multi-row INSERT into a table.
+type BulkUpsert struct {
+	Table      string   // ex. "coin_quote"
+	Columns    []string // inserted columns, every row holds one value per column in this order
+	OnConflict string   // optional ON CONFLICT clause (ex. "ON CONFLICT (cmc_id) DO UPDATE SET ...")
+	Returning  string   // optional RETURNING column list (ex. "cmc_id, id"), rows are passed to the scan func
+	BatchSize  int      // rows per statement, DefaultBulkBatchSize if 0
+}
+
+// RowError is a row rejected by the database, Index is the row's position in the rows passed to Exec.
+type RowError struct {
+	Index int
+	Err   error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+// BulkResult holds the outcome of a bulk write.
+type BulkResult struct {
+	Affected int64      // rows inserted or updated (returned rows if Returning is set)
+	Rejected []RowError // rows rejected by the database, the other rows are written
+}
+
+// Exec writes rows in tx. If Returning is set scan is called for every returned row. Rows rejected because of
+// their data are reported in BulkResult.Rejected, any other error is returned and tx should be rolled back.
+func (b BulkUpsert) Exec(ctx context.Context, tx *sql.Tx, rows [][]any, scan func(*sql.Rows) error) (BulkResult, error) {
+	var result BulkResult
+	if len(rows) == 0 {
+		return result, nil
+	}
+	if len(b.Columns) == 0 {
+		return result, fmt.Errorf("bulk upsert %s: no columns", b.Table)
+	}
+	if b.Returning != "" && scan == nil {
+		return result, fmt.Errorf("bulk upsert %s: scan func required with RETURNING", b.Table)
+	}
+	for i, row := range rows {
+		if len(row) != len(b.Columns) {
+			return result, fmt.Errorf("bulk upsert %s: row %d has %d values, expected %d", b.Table, i, len(row), len(b.Columns))
+		}
+	}
+
+	batchSize := b.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBulkBatchSize
+	}
+	batchSize = min(batchSize, maxBindParams/len(b.Columns))
+
+	for start := 0; start < len(rows); start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+		n, err := b.execSavepoint(ctx, tx, batch, scan)
+		if err == nil {
+			result.Affected += n
+			continue
+		}
+		if !IsDataError(err) {
+			return result, fmt.Errorf("bulk upsert %s: %w", b.Table, err)
+		}
+
+		// Find the rejected rows, one statement per row
+		for i, row := range batch {
+			n, err := b.execSavepoint(ctx, tx, [][]any{row}, scan)
+			if err == nil {
+				result.Affected += n
+				continue
+			}
+			if !IsDataError(err) {
+				return result, fmt.Errorf("bulk upsert %s: %w", b.Table, err)
+			}
+			result.Rejected = append(result.Rejected, RowError{Index: start + i, Err: err})
+		}
+	}
+	return result, nil
+}
+
+// execSavepoint runs one statement for rows under a savepoint, rolled back to if the statement fails so tx
+// can still be used.
+func (b BulkUpsert) execSavepoint(ctx context.Context, tx *sql.Tx, rows [][]any, scan func(*sql.Rows) error) (int64, error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_upsert"); err != nil {
+		return 0, err
+	}
+	n, err := b.exec(ctx, tx, rows, scan)
+	if err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_upsert"); rbErr != nil {
+			return 0, errors.Join(err, rbErr)
+		}
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_upsert"); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// exec runs one multi-row INSERT statement for rows.
+func (b BulkUpsert) exec(ctx context.Context, tx *sql.Tx, rows [][]any, scan func(*sql.Rows) error) (int64, error) {
+	query, args := b.statement(rows)
+	if b.Returning == "" {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+
+	// Postgres runs INSERT ... RETURNING to completion before sending rows, a rejected batch returns no rows
+	result, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer result.Close()
+	var n int64
+	for result.Next() {
+		if err := scan(result); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, result.Err()
+}
+
+// statement builds INSERT INTO table (columns) VALUES ($1, ...), (...) [ON CONFLICT ...] [RETURNING ...].
+func (b BulkUpsert) statement(rows [][]any) (string, []any) {
+	var sb strings.Builder
+	args := make([]any, 0, len(rows)*len(b.Columns))
+
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(b.Table)
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(b.Columns, ", "))
+	sb.WriteString(") VALUES ")
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for j, value := range row {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			args = append(args, value)
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(len(args)))
+		}
+		sb.WriteByte(')')
+	}
+	if b.OnConflict != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(strings.TrimSpace(b.OnConflict))
+	}
+	if b.Returning != "" {
+		sb.WriteString(" RETURNING ")
+		sb.WriteString(b.Returning)
+	}
+	return sb.String(), args
+}
+
+// IsDataError reports whether err was caused by the data of a row rather than the statement or connection:
+// Postgres error classes 21 (cardinality violation, ex. the same key twice in one ON CONFLICT DO UPDATE),
+// 22 (data exception, ex. numeric field overflow) and 23 (integrity constraint violation).
+func IsDataError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code.Class() {
+	case "21", "22", "23":
+		return true
+	}
+	return false
+}
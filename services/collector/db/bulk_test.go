@@ -0,0 +1,49 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// TestBulkUpsertStatement tests rows are numbered across a multi-row VALUES list with the conflict and returning clauses
+func TestBulkUpsertStatement(t *testing.T) {
+	b := BulkUpsert{
+		Table:      "coin_info",
+		Columns:    []string{"cmc_id", "name"},
+		OnConflict: "\n\t\tON CONFLICT (cmc_id) DO NOTHING",
+		Returning:  "cmc_id, id",
+	}
+	query, args := b.statement([][]any{{1, "Bitcoin"}, {1027, "Ethereum"}})
+
+	want := "INSERT INTO coin_info (cmc_id, name) VALUES ($1, $2), ($3, $4) ON CONFLICT (cmc_id) DO NOTHING RETURNING cmc_id, id"
+	if query != want {
+		t.Errorf("Expected query\n%s\ngot\n%s", want, query)
+	}
+	if len(args) != 4 || args[0] != 1 || args[3] != "Ethereum" {
+		t.Errorf("Unexpected args %v", args)
+	}
+}
+
+// TestIsDataError tests only row data errors trigger the row by row retry
+func TestIsDataError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "22003"}, true}, // numeric_value_out_of_range
+		{&pq.Error{Code: "23505"}, true}, // unique_violation
+		{&pq.Error{Code: "21000"}, true}, // cardinality_violation (same key twice in one batch)
+		{fmt.Errorf("wrapped: %w", &pq.Error{Code: "22P02"}), true},
+		{&pq.Error{Code: "42601"}, false}, // syntax_error
+		{&pq.Error{Code: "57014"}, false}, // query_canceled
+		{errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := IsDataError(tt.err); got != tt.want {
+			t.Errorf("IsDataError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
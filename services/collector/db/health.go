@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Runtime outages: database/sql reopens pool connections by itself once Postgres is back, Monitor pings the
+// pool to notice the outage (Health.Degraded, served on GET /status) and the recovery, when callers flush
+// the writes they buffered in the meantime.
+
+// Health holds the connection state of a Database.
+type Health struct {
+	Degraded  bool      `json:"degraded"`             // last ping failed, writes are expected to fail
+	Since     time.Time `json:"since"`                // time of the last state change
+	LastError string    `json:"last_error,omitempty"` // error of the last failed ping
+}
+
+// Health returns the connection state. Safe to call on a nil *Database (reported as degraded).
+func (d *Database) Health() Health {
+	if d == nil {
+		return Health{Degraded: true, LastError: ErrNotConnected.Error()}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.health
+}
+
+// setHealth records the result of a ping. Returns true if the state changed.
+func (d *Database) setHealth(err error) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	degraded := err != nil
+	changed := degraded != d.health.Degraded || d.health.Since.IsZero()
+	if changed {
+		d.health.Since = time.Now().UTC()
+	}
+	d.health.Degraded = degraded
+	d.health.LastError = ""
+	if err != nil {
+		d.health.LastError = err.Error()
+	}
+	return changed
+}
+
+// Monitor pings the database every interval until ctx is done. While degraded the ping is retried with a delay
+// starting at DB_CONNECT_RETRY_DELAY and doubling per attempt (max 30s). onRecover (optional) is called after
+// a degraded database answers again.
+func (d *Database) Monitor(ctx context.Context, interval time.Duration, onRecover func(ctx context.Context)) {
+	settings := d.cfg.DB
+	delay := interval
+	if d.Health().Degraded {
+		delay = settings.ConnectRetryDelay
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		wasDegraded := d.Health().Degraded
+		err := ping(ctx, d.db, settings.ConnectTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		d.setHealth(err)
+		switch {
+		case err != nil && !wasDegraded:
+			delay = settings.ConnectRetryDelay
+			d.logger.Warn("Database unreachable - degraded, reconnecting", "retry_in", delay, "error", err)
+		case err != nil:
+			delay = min(delay*2, maxConnectRetryDelay)
+			d.logger.Info("Database still unreachable", "retry_in", delay, "error", err)
+		case wasDegraded:
+			delay = interval
+			d.logger.Info("Database reconnected")
+			if onRecover != nil {
+				onRecover(ctx)
+			}
+		default:
+			delay = interval
+		}
+		delay = max(delay, time.Second)
+	}
+}
+
+// IsConnError reports whether err was caused by the connection rather than the statement or its data: network
+// errors, a closed connection, and Postgres error classes 08 (connection exception) and 57P (operator intervention,
+// ex. the server shutting down). A write failing with a connection error can be retried later. Context errors
+// aren't connection errors: the caller ran out of time or gave up, the database may be fine.
+func IsConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false // context.DeadlineExceeded is also a net.Error
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		code := string(pqErr.Code)
+		return pqErr.Code.Class() == "08" || code == "57P01" || code == "57P02" || code == "57P03"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
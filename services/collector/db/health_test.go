@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// TestIsConnError tests only connection failures are treated as retryable writes
+func TestIsConnError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("upsert coin_info: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{&pq.Error{Code: "08006"}, true}, // connection_failure
+		{&pq.Error{Code: "57P01"}, true}, // admin_shutdown
+		{context.DeadlineExceeded, false},
+		{fmt.Errorf("upsert coin_quote: %w", context.Canceled), false},
+		{&pq.Error{Code: "22003"}, false}, // numeric_value_out_of_range
+		{&pq.Error{Code: "42P01"}, false}, // undefined_table
+		{ErrNotConnected, false},
+	}
+	for _, tt := range tests {
+		if got := IsConnError(tt.err); got != tt.want {
+			t.Errorf("IsConnError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestHealth tests state changes are timestamped and a nil database reports degraded
+func TestHealth(t *testing.T) {
+	var missing *Database
+	if !missing.Health().Degraded {
+		t.Error("Expected a nil database to be degraded")
+	}
+
+	d := NewDatabaseFromDB(nil)
+	if !d.setHealth(nil) || d.Health().Degraded {
+		t.Fatal("Expected first healthy state to be recorded")
+	}
+	if d.setHealth(nil) {
+		t.Error("Expected no state change for a second healthy ping")
+	}
+	if !d.setHealth(ErrNotConnected) || !d.Health().Degraded || d.Health().LastError == "" {
+		t.Errorf("Expected degraded state with error, got %+v", d.Health())
+	}
+}
@@ -0,0 +1,348 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Schema migrations for the collector tables. The SQL files in db/migrations are embedded in the binary and
+// applied in version order, applied versions are recorded in the schema_migrations table. Each migration
+// runs in its own transaction together with its schema_migrations row so a failed migration leaves nothing
+// behind. A Postgres advisory lock makes sure only one collector instance migrates at a time.
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migrationLockKey is the pg_advisory_lock key held while migrating (any constant unique to this app).
+const migrationLockKey = 4207310
+
+const (
+	createSchemaMigrationsSQL = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`
+
+	selectSchemaMigrationsSQL = `SELECT version, applied_at FROM schema_migrations ORDER BY version`
+	insertSchemaMigrationSQL  = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+	deleteSchemaMigrationSQL  = `DELETE FROM schema_migrations WHERE version = $1`
+)
+
+// migrationFileName matches NNN_name.up.sql and NNN_name.down.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change with its up and down SQL.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus holds a migration and whether it is applied to the database.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time // zero if not applied
+}
+
+// Migrator applies the embedded migrations to a database.
+type Migrator struct {
+	conn       *sql.DB
+	migrations []Migration // ordered by version
+	logger     *slog.Logger
+}
+
+// NewMigrator creates a Migrator for the embedded migrations (db/migrations) on database.
+func NewMigrator(database *Database, logger *slog.Logger) (*Migrator, error) {
+	if database == nil || database.db == nil {
+		return nil, ErrNotConnected
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{conn: database.db, migrations: migrations, logger: logger}, nil
+}
+
+// LoadMigrations returns the embedded migrations ordered by version.
+func LoadMigrations() ([]Migration, error) {
+	return loadMigrations(migrationFS, "migrations")
+}
+
+// Latest returns the highest migration version, 0 if there are none.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies all pending migrations. Returns the number of migrations applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the last n applied migrations. Returns the number of migrations reverted.
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	if n < 1 {
+		return 0, fmt.Errorf("number of migrations to revert must be at least 1, got %d", n)
+	}
+	return m.run(ctx, func(applied map[int]time.Time) int {
+		versions := appliedVersions(applied)
+		if n >= len(versions) {
+			return 0
+		}
+		return versions[len(versions)-n-1]
+	})
+}
+
+// To migrates up or down so that exactly the migrations up to version are applied (0 reverts everything).
+// Returns the number of migrations applied or reverted.
+func (m *Migrator) To(ctx context.Context, version int) (int, error) {
+	if version < 0 || version > m.Latest() {
+		return 0, fmt.Errorf("unknown migration version %d (latest is %d)", version, m.Latest())
+	}
+	return m.run(ctx, func(map[int]time.Time) int { return version })
+}
+
+// Baseline records the migrations up to version as applied without running them, for databases whose schema
+// was applied by hand before schema_migrations existed. Returns the number of migrations recorded.
+func (m *Migrator) Baseline(ctx context.Context, version int) (int, error) {
+	if version < 1 || version > m.Latest() {
+		return 0, fmt.Errorf("unknown migration version %d (latest is %d)", version, m.Latest())
+	}
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlock(conn)
+
+	applied, err := selectAppliedMigrations(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	up, _ := planMigrations(m.migrations, applied, version)
+	for i, mig := range up {
+		if _, err := conn.ExecContext(ctx, insertSchemaMigrationSQL, mig.Version, mig.Name); err != nil {
+			return i, fmt.Errorf("record migration %03d_%s: %w", mig.Version, mig.Name, err)
+		}
+		m.logger.Info("Recorded migration as applied", "version", mig.Version, "name", mig.Name)
+	}
+	return len(up), nil
+}
+
+// Status returns every known migration and whether it is applied, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if _, err := m.conn.ExecContext(ctx, createSchemaMigrationsSQL); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	applied, err := selectAppliedMigrations(ctx, m.conn)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		appliedAt, ok := applied[mig.Version]
+		status = append(status, MigrationStatus{Version: mig.Version, Name: mig.Name, Applied: ok, AppliedAt: appliedAt})
+	}
+	return status, nil
+}
+
+// run takes the advisory lock, reads the applied versions and migrates to the version returned by target.
+func (m *Migrator) run(ctx context.Context, target func(applied map[int]time.Time) int) (int, error) {
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlock(conn)
+
+	applied, err := selectAppliedMigrations(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	up, down := planMigrations(m.migrations, applied, target(applied))
+	count := 0
+	for _, mig := range up {
+		if err := m.apply(ctx, conn, mig, mig.Up, insertSchemaMigrationSQL, mig.Version, mig.Name); err != nil {
+			return count, err
+		}
+		m.logger.Info("Applied migration", "version", mig.Version, "name", mig.Name)
+		count++
+	}
+	for _, mig := range down {
+		if err := m.apply(ctx, conn, mig, mig.Down, deleteSchemaMigrationSQL, mig.Version); err != nil {
+			return count, err
+		}
+		m.logger.Info("Reverted migration", "version", mig.Version, "name", mig.Name)
+		count++
+	}
+	return count, nil
+}
+
+// lock takes a connection, waits for the migration advisory lock and makes sure schema_migrations exists.
+// Advisory locks belong to a session so the same connection is used for the whole run.
+func (m *Migrator) lock(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.conn.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get connection: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, createSchemaMigrationsSQL); err != nil {
+		m.unlock(conn)
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return conn, nil
+}
+
+// unlock releases the migration advisory lock and returns the connection to the pool.
+func (m *Migrator) unlock(conn *sql.Conn) {
+	// Unlock even if ctx is done, the session would otherwise keep the lock while the connection is pooled
+	if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+		m.logger.Error("failed to release migration lock", "error", err)
+	}
+	conn.Close()
+}
+
+// apply runs a migration's SQL and records it in schema_migrations in one transaction.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, script, recordSQL string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration %03d_%s: %w", mig.Version, mig.Name, err)
+	}
+	defer tx.Rollback() // no-op after commit
+
+	// Scripts hold several statements, run without parameters so the driver sends them as one simple query
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, recordSQL, args...); err != nil {
+		return fmt.Errorf("record migration %03d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %03d_%s: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+// planMigrations returns the pending migrations up to target (ascending) and the applied migrations above
+// target to revert (descending).
+func planMigrations(migrations []Migration, applied map[int]time.Time, target int) (up, down []Migration) {
+	for _, mig := range migrations {
+		if _, ok := applied[mig.Version]; !ok && mig.Version <= target {
+			up = append(up, mig)
+		}
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[migrations[i].Version]; ok && migrations[i].Version > target {
+			down = append(down, migrations[i])
+		}
+	}
+	return up, down
+}
+
+// appliedVersions returns the applied versions in ascending order.
+func appliedVersions(applied map[int]time.Time) []int {
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// queryer is implemented by *sql.DB and *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// selectAppliedMigrations returns the applied versions and when they were applied.
+func selectAppliedMigrations(ctx context.Context, q queryer) (map[int]time.Time, error) {
+	rows, err := q.QueryContext(ctx, selectSchemaMigrationsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("select schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("scan schema_migrations row: %w", err)
+		}
+		applied[version] = appliedAt.UTC()
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate schema_migrations rows: %w", err)
+	}
+	return applied, nil
+}
+
+// loadMigrations reads NNN_name.up.sql / NNN_name.down.sql pairs from dir. Every version needs both files.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s (expected NNN_name.up.sql or NNN_name.down.sql)", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version < 1 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by %s and %s", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s needs both an up and a down file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
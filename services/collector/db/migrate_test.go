@@ -0,0 +1,84 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// TestLoadMigrations tests the embedded migrations load in version order with both up and down SQL
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+	for i, mig := range migrations {
+		if mig.Version != i+1 {
+			t.Errorf("Expected version %d, got %d (%s)", i+1, mig.Version, mig.Name)
+		}
+		if mig.Up == "" || mig.Down == "" {
+			t.Errorf("Expected up and down SQL for %03d_%s", mig.Version, mig.Name)
+		}
+	}
+}
+
+// TestLoadMigrationsInvalid tests file sets the runner can't apply are rejected
+func TestLoadMigrationsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "missing down",
+			files:   fstest.MapFS{"m/001_a.up.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: "needs both",
+		},
+		{
+			name: "duplicate version",
+			files: fstest.MapFS{
+				"m/001_a.up.sql": {Data: []byte("SELECT 1;")}, "m/001_a.down.sql": {Data: []byte("SELECT 1;")},
+				"m/001_b.up.sql": {Data: []byte("SELECT 1;")}, "m/001_b.down.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "version 1 used by",
+		},
+		{
+			name:    "bad name",
+			files:   fstest.MapFS{"m/create_table.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: "invalid migration file name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadMigrations(tt.files, "m")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestPlanMigrations tests pending migrations are applied in order and reverted newest first
+func TestPlanMigrations(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}, {Version: 4}}
+	applied := map[int]time.Time{1: {}, 2: {}}
+
+	up, down := planMigrations(migrations, applied, 4)
+	if len(up) != 2 || up[0].Version != 3 || up[1].Version != 4 || len(down) != 0 {
+		t.Errorf("Expected up [3 4], got up %v down %v", up, down)
+	}
+
+	up, down = planMigrations(migrations, applied, 0)
+	if len(up) != 0 || len(down) != 2 || down[0].Version != 2 || down[1].Version != 1 {
+		t.Errorf("Expected down [2 1], got up %v down %v", up, down)
+	}
+
+	up, down = planMigrations(migrations, applied, 2)
+	if len(up) != 0 || len(down) != 0 {
+		t.Errorf("Expected nothing to do, got up %v down %v", up, down)
+	}
+}
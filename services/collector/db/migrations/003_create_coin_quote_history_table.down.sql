@@ -0,0 +1,4 @@
+-- Migration: create_coin_quote_history_table (rollback)
+-- Description: Drops the coin_quote_history table
+
+DROP TABLE IF EXISTS coin_quote_history;
@@ -0,0 +1,20 @@
+-- Migration: create_coin_quote_history_table
+-- Description: Creates the append-only coin_quote_history table to store every quote fetched from Coinmarketcap API
+-- Maps to: ticker.CoinQuote struct (written next to coin_quote), read as ticker.HistoricalQuote
+-- Note: (coin_id, last_updated) is unique so repeated CMC timestamps are only stored once.
+
+CREATE TABLE IF NOT EXISTS coin_quote_history (
+    id BIGSERIAL PRIMARY KEY,
+    coin_id INT NOT NULL REFERENCES coin_info(id) ON DELETE CASCADE,
+    price NUMERIC(20, 8) NOT NULL,
+    market_cap NUMERIC(20, 2),
+    fully_diluted_market_cap NUMERIC(20, 2),
+    volume_24h NUMERIC(20, 2),
+    percent_change_1h NUMERIC(10, 4),
+    percent_change_24h NUMERIC(10, 4),
+    percent_change_7d NUMERIC(10, 4),
+    last_updated TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- One row per coin per CMC update timestamp (also serves time range queries per coin)
+    CONSTRAINT unique_coin_quote_history UNIQUE(coin_id, last_updated)
+);
@@ -0,0 +1,6 @@
+-- Migration: create_tracked_coins_table (rollback)
+-- Description: Drops the tracked_coins table and its indexes
+
+DROP INDEX IF EXISTS idx_tracked_coins_enabled;
+DROP INDEX IF EXISTS idx_tracked_coins_symbol;
+DROP TABLE IF EXISTS tracked_coins;
@@ -0,0 +1,18 @@
+-- Migration: create_tracked_coins_table
+-- Description: Creates the tracked_coins table, source of truth for which coins the ticker fetches
+-- Maps to: coins.TrackedCoin struct
+-- Note: linked to coin_info by cmc_id (no FK, coin_info rows are created by the ticker).
+
+CREATE TABLE IF NOT EXISTS tracked_coins (
+    id SERIAL PRIMARY KEY,
+    cmc_id INT NOT NULL UNIQUE,
+    symbol VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_tracked_coins_symbol ON tracked_coins(symbol);
+CREATE INDEX IF NOT EXISTS idx_tracked_coins_enabled ON tracked_coins(enabled);
@@ -0,0 +1,6 @@
+-- Migration: create_id_map_table (rollback)
+-- Description: Drops the id_map table and its indexes
+
+DROP INDEX IF EXISTS idx_id_map_rank;
+DROP INDEX IF EXISTS idx_id_map_symbol;
+DROP TABLE IF EXISTS id_map;
@@ -0,0 +1,30 @@
+-- Migration: create_id_map_table
+-- Description: Creates the id_map table, a local copy of the Coinmarketcap ID map (/v1/cryptocurrency/map)
+-- Maps to: mapper.CmcCoinID struct
+-- Note: written by the mapper sync job. Assets missing from a complete sync are kept and marked with delisted_at.
+
+CREATE TABLE IF NOT EXISTS id_map (
+    id SERIAL PRIMARY KEY,
+    cmc_id INT NOT NULL UNIQUE,
+    rank INT,
+    name VARCHAR(255) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL,
+    listing_status VARCHAR(20) NOT NULL, -- active, inactive or untracked (CMC listing_status query)
+    first_historical_data TIMESTAMP,
+    last_historical_data TIMESTAMP,
+    platform_id INT,
+    platform_name VARCHAR(255),
+    platform_symbol VARCHAR(50),
+    platform_slug VARCHAR(255),
+    platform_token_address VARCHAR(255),
+    delisted_at TIMESTAMP,
+    last_synced_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_id_map_symbol ON id_map(symbol);
+CREATE INDEX IF NOT EXISTS idx_id_map_rank ON id_map(rank);
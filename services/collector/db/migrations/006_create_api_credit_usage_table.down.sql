@@ -0,0 +1,5 @@
+-- Migration: create_api_credit_usage_table (rollback)
+-- Description: Drops the api_credit_usage table and its indexes
+
+DROP INDEX IF EXISTS idx_api_credit_usage_recorded_at;
+DROP TABLE IF EXISTS api_credit_usage;
@@ -0,0 +1,14 @@
+-- Migration: create_api_credit_usage_table
+-- Description: Creates the api_credit_usage table to store Coinmarketcap API credits used per call (status.credit_count)
+-- Maps to: credits.Usage struct
+-- Note: read at startup to restore the daily and monthly credit totals.
+
+CREATE TABLE IF NOT EXISTS api_credit_usage (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint VARCHAR(50) NOT NULL,
+    credits INT NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for daily and monthly totals
+CREATE INDEX IF NOT EXISTS idx_api_credit_usage_recorded_at ON api_credit_usage(recorded_at);
@@ -0,0 +1,5 @@
+-- Migration: create_provider_id_map_table (rollback)
+-- Description: Drops the provider_id_map table and its indexes
+
+DROP INDEX IF EXISTS idx_provider_id_map_provider_coin_id;
+DROP TABLE IF EXISTS provider_id_map;
@@ -0,0 +1,61 @@
+-- Migration: create_provider_id_map_table
+-- Description: Creates the provider_id_map table mapping Coinmarketcap IDs to other price providers' coin IDs
+-- Maps to: coingecko.ProviderIDMap interface (coingecko.DBIDMap)
+-- Note: coins are tracked by CMC ID; rows are needed for every tracked coin a secondary provider should quote.
+
+CREATE TABLE IF NOT EXISTS provider_id_map (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    cmc_id INT NOT NULL,
+    provider_coin_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(provider, cmc_id)
+);
+
+-- Index for reverse lookups (provider ID -> CMC ID)
+CREATE INDEX IF NOT EXISTS idx_provider_id_map_provider_coin_id ON provider_id_map(provider, provider_coin_id);
+
+-- Seed CoinGecko IDs of well-known coins
+INSERT INTO provider_id_map (provider, cmc_id, provider_coin_id) VALUES
+    ('coingecko', 1, 'bitcoin'),
+    ('coingecko', 1027, 'ethereum'),
+    ('coingecko', 825, 'tether'),
+    ('coingecko', 52, 'ripple'),
+    ('coingecko', 1839, 'binancecoin'),
+    ('coingecko', 5426, 'solana'),
+    ('coingecko', 3408, 'usd-coin'),
+    ('coingecko', 74, 'dogecoin'),
+    ('coingecko', 1958, 'tron'),
+    ('coingecko', 2010, 'cardano'),
+    ('coingecko', 32196, 'hyperliquid'),
+    ('coingecko', 1975, 'chainlink'),
+    ('coingecko', 20947, 'sui'),
+    ('coingecko', 512, 'stellar'),
+    ('coingecko', 5805, 'avalanche-2'),
+    ('coingecko', 1831, 'bitcoin-cash'),
+    ('coingecko', 4642, 'hedera-hashgraph'),
+    ('coingecko', 2, 'litecoin'),
+    ('coingecko', 11419, 'the-open-network'),
+    ('coingecko', 5994, 'shiba-inu'),
+    ('coingecko', 6636, 'polkadot'),
+    ('coingecko', 328, 'monero'),
+    ('coingecko', 4943, 'dai'),
+    ('coingecko', 24478, 'pepe'),
+    ('coingecko', 7278, 'aave'),
+    ('coingecko', 7083, 'uniswap'),
+    ('coingecko', 6535, 'near'),
+    ('coingecko', 21794, 'aptos'),
+    ('coingecko', 8916, 'internet-computer'),
+    ('coingecko', 1321, 'ethereum-classic'),
+    ('coingecko', 3717, 'wrapped-bitcoin'),
+    ('coingecko', 11841, 'arbitrum'),
+    ('coingecko', 4030, 'algorand'),
+    ('coingecko', 3794, 'cosmos'),
+    ('coingecko', 2280, 'filecoin'),
+    ('coingecko', 20396, 'kaspa'),
+    ('coingecko', 11840, 'optimism'),
+    ('coingecko', 1437, 'zcash'),
+    ('coingecko', 7226, 'injective-protocol'),
+    ('coingecko', 22861, 'celestia')
+ON CONFLICT (provider, cmc_id) DO NOTHING;
@@ -0,0 +1,5 @@
+-- Migration: create_quote_disagreement_table (rollback)
+-- Description: Drops the quote_disagreement table and its indexes
+
+DROP INDEX IF EXISTS idx_quote_disagreement_cmc_id_recorded_at;
+DROP TABLE IF EXISTS quote_disagreement;
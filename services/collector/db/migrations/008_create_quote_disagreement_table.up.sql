@@ -0,0 +1,20 @@
+-- Migration: create_quote_disagreement_table
+-- Description: Creates the quote_disagreement table to flag coins whose price providers disagree beyond DISAGREE_THRESHOLD
+-- Maps to: ticker.Disagreement struct
+-- Note: append-only, written by UpdateDB when a secondary price provider is enabled. No FK so coins can be flagged before coin_info exists.
+
+CREATE TABLE IF NOT EXISTS quote_disagreement (
+    id BIGSERIAL PRIMARY KEY,
+    cmc_id INT NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    primary_provider VARCHAR(50) NOT NULL,
+    primary_price NUMERIC(20, 8) NOT NULL,
+    secondary_provider VARCHAR(50) NOT NULL,
+    secondary_price NUMERIC(20, 8) NOT NULL,
+    deviation NUMERIC(12, 6) NOT NULL,
+    stored_price NUMERIC(20, 8) NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for per-coin lookups over time
+CREATE INDEX IF NOT EXISTS idx_quote_disagreement_cmc_id_recorded_at ON quote_disagreement(cmc_id, recorded_at);
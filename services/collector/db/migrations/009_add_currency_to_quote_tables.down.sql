@@ -0,0 +1,13 @@
+-- Migration: add_currency_to_quote_tables (rollback)
+-- Description: Removes the currency column from coin_quote and coin_quote_history
+-- Note: quotes in currencies other than USD are deleted.
+
+DELETE FROM coin_quote_history WHERE currency <> 'USD';
+ALTER TABLE coin_quote_history DROP CONSTRAINT IF EXISTS unique_coin_quote_history_currency;
+ALTER TABLE coin_quote_history ADD CONSTRAINT unique_coin_quote_history UNIQUE(coin_id, last_updated);
+ALTER TABLE coin_quote_history DROP COLUMN IF EXISTS currency;
+
+DELETE FROM coin_quote WHERE currency <> 'USD';
+ALTER TABLE coin_quote DROP CONSTRAINT IF EXISTS unique_coin_quote_currency;
+ALTER TABLE coin_quote ADD CONSTRAINT unique_coin_quote UNIQUE(coin_id);
+ALTER TABLE coin_quote DROP COLUMN IF EXISTS currency;
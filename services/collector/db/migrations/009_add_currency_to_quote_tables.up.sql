@@ -0,0 +1,14 @@
+-- Migration: add_currency_to_quote_tables
+-- Description: Adds a currency column to coin_quote and coin_quote_history so quotes are stored per convert currency (CMC_CONVERT)
+-- Maps to: ticker.CoinInfo.Quote map keys (ex. USD, CAD, EUR, BTC), ticker.HistoricalQuote.Currency
+-- Note: existing rows were fetched with convert=USD and default to 'USD'.
+
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS currency VARCHAR(10) NOT NULL DEFAULT 'USD';
+ALTER TABLE coin_quote DROP CONSTRAINT IF EXISTS unique_coin_quote;
+-- One quote per coin and currency (most recent)
+ALTER TABLE coin_quote ADD CONSTRAINT unique_coin_quote_currency UNIQUE(coin_id, currency);
+
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS currency VARCHAR(10) NOT NULL DEFAULT 'USD';
+ALTER TABLE coin_quote_history DROP CONSTRAINT IF EXISTS unique_coin_quote_history;
+-- One row per coin, currency and CMC update timestamp (also serves time range queries per coin and currency)
+ALTER TABLE coin_quote_history ADD CONSTRAINT unique_coin_quote_history_currency UNIQUE(coin_id, currency, last_updated);
@@ -0,0 +1,27 @@
+-- Migration: add_reference_metrics (rollback)
+-- Description: Drops the reference metric columns from coin_info, coin_quote and coin_quote_history
+
+ALTER TABLE coin_quote_history
+    DROP COLUMN IF EXISTS volume_24h_reported,
+    DROP COLUMN IF EXISTS volume_change_24h,
+    DROP COLUMN IF EXISTS percent_change_30d,
+    DROP COLUMN IF EXISTS percent_change_60d,
+    DROP COLUMN IF EXISTS percent_change_90d,
+    DROP COLUMN IF EXISTS market_cap_dominance,
+    DROP COLUMN IF EXISTS tvl;
+
+ALTER TABLE coin_quote
+    DROP COLUMN IF EXISTS volume_24h_reported,
+    DROP COLUMN IF EXISTS volume_change_24h,
+    DROP COLUMN IF EXISTS percent_change_30d,
+    DROP COLUMN IF EXISTS percent_change_60d,
+    DROP COLUMN IF EXISTS percent_change_90d,
+    DROP COLUMN IF EXISTS market_cap_dominance,
+    DROP COLUMN IF EXISTS tvl;
+
+ALTER TABLE coin_info
+    DROP COLUMN IF EXISTS max_supply,
+    DROP COLUMN IF EXISTS infinite_supply,
+    DROP COLUMN IF EXISTS self_reported_circulating_supply,
+    DROP COLUMN IF EXISTS self_reported_market_cap,
+    DROP COLUMN IF EXISTS tvl_ratio;
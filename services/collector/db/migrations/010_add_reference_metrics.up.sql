@@ -0,0 +1,26 @@
+-- Migration: add_reference_metrics
+-- Description: Adds supply metrics to coin_info and 30/60/90 day changes, reported volume, dominance and TVL to the quote tables
+-- Maps to: ticker.CoinInfo and ticker.CoinQuote pointer fields (promoted from types_reference.go)
+-- Note: all columns are nullable, CMC returns null for unknown values (ex. percent_change_90d of a new listing).
+
+ALTER TABLE coin_info ADD COLUMN IF NOT EXISTS max_supply NUMERIC(20, 8);
+ALTER TABLE coin_info ADD COLUMN IF NOT EXISTS infinite_supply BOOLEAN;
+ALTER TABLE coin_info ADD COLUMN IF NOT EXISTS self_reported_circulating_supply NUMERIC(20, 8);
+ALTER TABLE coin_info ADD COLUMN IF NOT EXISTS self_reported_market_cap NUMERIC(20, 2);
+ALTER TABLE coin_info ADD COLUMN IF NOT EXISTS tvl_ratio NUMERIC(20, 8);
+
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS volume_24h_reported NUMERIC(20, 2);
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS volume_change_24h NUMERIC(10, 4);
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS percent_change_30d NUMERIC(10, 4);
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS percent_change_60d NUMERIC(10, 4);
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS percent_change_90d NUMERIC(10, 4);
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS market_cap_dominance NUMERIC(10, 4);
+ALTER TABLE coin_quote ADD COLUMN IF NOT EXISTS tvl NUMERIC(20, 2);
+
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS volume_24h_reported NUMERIC(20, 2);
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS volume_change_24h NUMERIC(10, 4);
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS percent_change_30d NUMERIC(10, 4);
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS percent_change_60d NUMERIC(10, 4);
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS percent_change_90d NUMERIC(10, 4);
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS market_cap_dominance NUMERIC(10, 4);
+ALTER TABLE coin_quote_history ADD COLUMN IF NOT EXISTS tvl NUMERIC(20, 2);
@@ -0,0 +1,47 @@
+-- Migration: widen_numeric_columns
+-- Description: Widens price, amount, supply and percent columns that real CMC data overflows
+-- Maps to: decimal.Decimal fields of ticker.CoinInfo and ticker.CoinQuote, column limits in internal/ticker/validate.go
+-- Note: prices are NUMERIC(38, 18) for micro-cap tokens (1e-9), supplies NUMERIC(38, 8) for quadrillion supplies,
+-- market caps and volumes NUMERIC(38, 2), percent changes NUMERIC(20, 4).
+
+ALTER TABLE coin_info
+    ALTER COLUMN circulating_supply TYPE NUMERIC(38, 8),
+    ALTER COLUMN total_supply TYPE NUMERIC(38, 8),
+    ALTER COLUMN max_supply TYPE NUMERIC(38, 8),
+    ALTER COLUMN self_reported_circulating_supply TYPE NUMERIC(38, 8),
+    ALTER COLUMN self_reported_market_cap TYPE NUMERIC(38, 2);
+
+ALTER TABLE coin_quote
+    ALTER COLUMN price TYPE NUMERIC(38, 18),
+    ALTER COLUMN market_cap TYPE NUMERIC(38, 2),
+    ALTER COLUMN fully_diluted_market_cap TYPE NUMERIC(38, 2),
+    ALTER COLUMN volume_24h TYPE NUMERIC(38, 2),
+    ALTER COLUMN volume_24h_reported TYPE NUMERIC(38, 2),
+    ALTER COLUMN tvl TYPE NUMERIC(38, 2),
+    ALTER COLUMN percent_change_1h TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_24h TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_7d TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_30d TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_60d TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_90d TYPE NUMERIC(20, 4),
+    ALTER COLUMN volume_change_24h TYPE NUMERIC(20, 4);
+
+ALTER TABLE coin_quote_history
+    ALTER COLUMN price TYPE NUMERIC(38, 18),
+    ALTER COLUMN market_cap TYPE NUMERIC(38, 2),
+    ALTER COLUMN fully_diluted_market_cap TYPE NUMERIC(38, 2),
+    ALTER COLUMN volume_24h TYPE NUMERIC(38, 2),
+    ALTER COLUMN volume_24h_reported TYPE NUMERIC(38, 2),
+    ALTER COLUMN tvl TYPE NUMERIC(38, 2),
+    ALTER COLUMN percent_change_1h TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_24h TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_7d TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_30d TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_60d TYPE NUMERIC(20, 4),
+    ALTER COLUMN percent_change_90d TYPE NUMERIC(20, 4),
+    ALTER COLUMN volume_change_24h TYPE NUMERIC(20, 4);
+
+ALTER TABLE quote_disagreement
+    ALTER COLUMN primary_price TYPE NUMERIC(38, 18),
+    ALTER COLUMN secondary_price TYPE NUMERIC(38, 18),
+    ALTER COLUMN stored_price TYPE NUMERIC(38, 18);
@@ -0,0 +1,38 @@
+-- Migration: timestamptz_columns (rollback)
+-- Description: Converts the TIMESTAMPTZ columns of the collector tables back to TIMESTAMP (UTC wall time)
+
+ALTER TABLE quote_disagreement
+    ALTER COLUMN recorded_at TYPE TIMESTAMP USING recorded_at AT TIME ZONE 'UTC';
+
+ALTER TABLE provider_id_map
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE api_credit_usage
+    ALTER COLUMN recorded_at TYPE TIMESTAMP USING recorded_at AT TIME ZONE 'UTC';
+
+ALTER TABLE id_map
+    ALTER COLUMN first_historical_data TYPE TIMESTAMP USING first_historical_data AT TIME ZONE 'UTC',
+    ALTER COLUMN last_historical_data TYPE TIMESTAMP USING last_historical_data AT TIME ZONE 'UTC',
+    ALTER COLUMN delisted_at TYPE TIMESTAMP USING delisted_at AT TIME ZONE 'UTC',
+    ALTER COLUMN last_synced_at TYPE TIMESTAMP USING last_synced_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE tracked_coins
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE coin_quote_history
+    ALTER COLUMN last_updated TYPE TIMESTAMP USING last_updated AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+
+ALTER TABLE coin_quote
+    ALTER COLUMN last_updated TYPE TIMESTAMP USING last_updated AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE coin_info
+    ALTER COLUMN last_updated TYPE TIMESTAMP USING last_updated AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
@@ -0,0 +1,6 @@
+-- Migration: widen_coin_info_symbol (rollback)
+-- Description: Restores the original coin_info.symbol size
+-- Note: fails if a stored symbol is longer than 10 characters.
+
+ALTER TABLE coin_info
+    ALTER COLUMN symbol TYPE VARCHAR(10);
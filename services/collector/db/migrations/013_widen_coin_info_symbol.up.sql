@@ -0,0 +1,7 @@
+-- Migration: widen_coin_info_symbol
+-- Description: Widens coin_info.symbol to the size of tracked_coins.symbol and id_map.symbol
+-- Maps to: ticker.CoinInfo.Symbol
+-- Note: CMC symbols longer than 10 characters were rejected with their coin.
+
+ALTER TABLE coin_info
+    ALTER COLUMN symbol TYPE VARCHAR(50);
@@ -1,52 +1,187 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/jdbdev/go-cmc/config"
 	_ "github.com/lib/pq"
 )
 
+// maxConnectRetryDelay caps the delay between startup connection attempts
+const maxConnectRetryDelay = 30 * time.Second
+
 // Database represents a database connection and its methods
 type Database struct {
-	db  *sql.DB           // Database connection instance
-	cfg *config.AppConfig // Application configuration settings
+	db     *sql.DB           // Database connection instance
+	cfg    *config.AppConfig // Application configuration settings
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	health Health // updated by Monitor (health.go)
 }
 
-// NewDatabase creates and returns a new Database instance
-func NewDatabase(cfg *config.AppConfig) (*Database, error) {
+// NewDatabase creates and returns a new Database instance. The first connection is retried
+// (DB_CONNECT_RETRIES, DB_CONNECT_RETRY_DELAY) so the collector can start before Postgres is ready.
+func NewDatabase(ctx context.Context, cfg *config.AppConfig, logger *slog.Logger) (*Database, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	database := &Database{
-		cfg: cfg,
+		cfg:    cfg,
+		logger: logger,
 	}
 
-	if err := database.connect(); err != nil {
+	if err := database.connect(ctx); err != nil {
 		return nil, err
 	}
+	database.setHealth(nil)
+
+	return database, nil
+}
 
+// OpenDatabase creates a Database without waiting for Postgres to answer (ex. NewDatabase ran out of retries).
+// It starts degraded, Monitor marks it healthy once a ping succeeds. Only a bad configuration returns an error.
+func OpenDatabase(cfg *config.AppConfig, logger *slog.Logger) (*Database, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	db, err := open(cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+	database := &Database{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+	database.setHealth(ErrNotConnected)
 	return database, nil
 }
 
-// connect establishes a connection to the database using the stored configuration
-func (d *Database) connect() error {
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		d.cfg.DB.Host, d.cfg.DB.Port, d.cfg.DB.User, d.cfg.DB.Password, d.cfg.DB.DBName)
+// NewDatabaseFromDB wraps an open connection pool (ex. tests and benchmarks opening their own DSN).
+func NewDatabaseFromDB(conn *sql.DB) *Database {
+	return &Database{db: conn, cfg: &config.AppConfig{}, logger: slog.Default()}
+}
 
+// open opens the connection pool with the configured limits. No connection is made yet.
+func open(settings config.DBSettings) (*sql.DB, error) {
+	connStr, err := DSN(settings)
+	if err != nil {
+		return nil, err
+	}
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		return fmt.Errorf("error connecting to the database: %v", err)
+		return nil, fmt.Errorf("error connecting to the database: %w", err)
+	}
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	db.SetMaxIdleConns(settings.MaxIdleConns)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	return db, nil
+}
+
+// connect opens the connection pool and pings it until it answers or the retries run out.
+func (d *Database) connect(ctx context.Context) error {
+	settings := d.cfg.DB
+	db, err := open(settings)
+	if err != nil {
+		return err
 	}
 
-	// Verify connection
-	if err := db.Ping(); err != nil {
-		db.Close() // Clean up before returning error
-		return fmt.Errorf("error pinging the database: %v", err)
+	// Verify connection, retrying with a doubling delay while the database is unreachable
+	delay := settings.ConnectRetryDelay
+	for attempt := 0; ; attempt++ {
+		err = ping(ctx, db, settings.ConnectTimeout)
+		if err == nil {
+			break
+		}
+		if attempt >= settings.ConnectRetries {
+			db.Close() // Clean up before returning error
+			return fmt.Errorf("error pinging the database after %d attempts: %w", attempt+1, err)
+		}
+		d.logger.Warn("Database not reachable - retrying",
+			"host", dsnHost(settings), "attempt", attempt+1, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return fmt.Errorf("error pinging the database: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxConnectRetryDelay)
 	}
 
 	d.db = db
 	return nil
 }
 
+// ping checks the database answers within timeout (0 = no timeout other than ctx).
+func ping(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return db.PingContext(ctx)
+}
+
+// DSN returns the lib/pq connection string for the settings: DATABASE_URL as is if set, otherwise a key/value
+// string built from the host, credentials, TLS settings and connect timeout.
+func DSN(settings config.DBSettings) (string, error) {
+	if settings.URL != "" {
+		return settings.URL, nil
+	}
+	switch settings.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		return "", fmt.Errorf("unsupported DB_SSLMODE %q (disable, require, verify-ca or verify-full)", settings.SSLMode)
+	}
+
+	params := []string{
+		"host=" + dsnValue(settings.Host),
+		"port=" + dsnValue(settings.Port),
+		"user=" + dsnValue(settings.User),
+		"password=" + dsnValue(settings.Password),
+		"dbname=" + dsnValue(settings.DBName),
+		"sslmode=" + settings.SSLMode,
+	}
+	if settings.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+dsnValue(settings.SSLRootCert))
+	}
+	if settings.ConnectTimeout > 0 {
+		// lib/pq takes whole seconds
+		params = append(params, fmt.Sprintf("connect_timeout=%d", int(math.Ceil(settings.ConnectTimeout.Seconds()))))
+	}
+	return strings.Join(params, " "), nil
+}
+
+// dsnValue quotes a key/value connection string value if it is empty or holds spaces, quotes or backslashes.
+func dsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// dsnHost returns the host the settings connect to, for logs (never the credentials).
+func dsnHost(settings config.DBSettings) string {
+	if settings.URL == "" {
+		return settings.Host
+	}
+	if u, err := url.Parse(settings.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "DATABASE_URL"
+}
+
 // Close closes the database connection
 func (d *Database) Close() error {
 	if d.db != nil {
@@ -54,8 +189,3 @@ func (d *Database) Close() error {
 	}
 	return nil
 }
-
-// GetDB returns the underlying *sql.DB instance
-func (d *Database) GetDB() *sql.DB {
-	return d.db
-}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+)
+
+// TestDSN tests the connection string built from the settings
+func TestDSN(t *testing.T) {
+	settings := config.DBSettings{
+		Host: "db.example.com", Port: "5432", User: "collector", Password: `p@ss 'w\rd`, DBName: "cmc",
+		SSLMode: "verify-full", SSLRootCert: "/etc/ssl/rds ca.pem", ConnectTimeout: 2500 * time.Millisecond,
+	}
+	got, err := DSN(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `host=db.example.com port=5432 user=collector password='p@ss \'w\\rd' dbname=cmc sslmode=verify-full ` +
+		`sslrootcert='/etc/ssl/rds ca.pem' connect_timeout=3`
+	if got != want {
+		t.Errorf("Expected DSN\n%s\ngot\n%s", want, got)
+	}
+
+	// DATABASE_URL overrides everything else
+	settings.URL = "postgres://u:p@managed.example.com:6543/cmc?sslmode=require"
+	if got, _ := DSN(settings); got != settings.URL {
+		t.Errorf("Expected DATABASE_URL, got %s", got)
+	}
+	if host := dsnHost(settings); host != "managed.example.com:6543" {
+		t.Errorf("Expected host managed.example.com:6543, got %s", host)
+	}
+
+	if _, err := DSN(config.DBSettings{SSLMode: "prefer"}); err == nil {
+		t.Error("Expected error for unsupported sslmode, got nil")
+	}
+}
+
+// TestNewDatabaseRetries tests the startup ping is retried and gives up with an error on an unreachable database
+func TestNewDatabaseRetries(t *testing.T) {
+	// Reserve a local port then close it so connections are refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	cfg := &config.AppConfig{DB: config.DBSettings{
+		Host: host, Port: port, User: "u", Password: "p", DBName: "d", SSLMode: "disable",
+		ConnectTimeout: time.Second, ConnectRetries: 2, ConnectRetryDelay: 10 * time.Millisecond,
+	}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	_, err = NewDatabase(context.Background(), cfg, logger)
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("Expected error after 3 attempts, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Repositories (ticker.CoinInfoRepo, ticker.QuoteRepo, coins.TrackedCoinRepo, mapper.IDMapRepo, ...) are
+// defined by the services that use them, with a Postgres implementation over a *Database and an in-memory
+// implementation for tests. Postgres repositories run their statements on Database.Querier: the transaction
+// started by WithTx if ctx carries one, the connection pool otherwise. Repositories sharing a *Database
+// therefore share a transaction: repo.WithTx(ctx, func(ctx) error { ... }) groups writes across repositories.
+
+// ErrNotConnected is returned by repositories when the database is disabled (USE_DB=false) or not connected.
+var ErrNotConnected = errors.New("database not connected")
+
+// Querier is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// TxRunner runs a function in a transaction. Embedded in every repository interface.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txKey is the context key of the transaction started by WithTx
+type txKey struct{}
+
+// TxFromContext returns the transaction started by WithTx for ctx.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// Querier returns the transaction carried by ctx or the connection pool. Safe to call on a nil *Database.
+func (d *Database) Querier(ctx context.Context) (Querier, error) {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx, nil
+	}
+	if !d.Connected() {
+		return nil, ErrNotConnected
+	}
+	return d.db, nil
+}
+
+// Connected returns true if the database is connected. Safe to call on a nil *Database.
+func (d *Database) Connected() bool {
+	return d != nil && d.db != nil
+}
+
+// WithTx runs fn in a transaction carried by the ctx passed to fn, committed if fn returns nil and rolled back
+// otherwise. If ctx already carries a transaction fn joins it and the outer WithTx commits or rolls back.
+func (d *Database) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	if !d.Connected() {
+		return ErrNotConnected
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // no-op after Commit
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// NopTx is the TxRunner of in-memory repositories: fn runs directly and nothing is rolled back if it fails.
+type NopTx struct{}
+
+// WithTx runs fn with ctx.
+func (NopTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,181 @@
+package cmc
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Circuit breaker in front of all CMC calls. After Threshold consecutive failed calls the breaker opens
+// and calls fail fast with ErrCircuitOpen until the cool-down has passed. The breaker then goes
+// half-open and lets a single probe call through: success closes it, failure opens it again.
+// State transitions are logged once, rejected calls are not logged.
+
+// ErrCircuitOpen is returned without calling CMC while the breaker is open (or half-open with a probe in flight).
+var ErrCircuitOpen = errors.New("CMC circuit breaker open")
+
+// BreakerState is the state of the circuit breaker.
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String returns the state name used in logs and the status endpoint.
+func (s BreakerState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerStatus holds a snapshot of the breaker for the status endpoint.
+type BreakerStatus struct {
+	State     string     `json:"state"`
+	Failures  int        `json:"consecutive_failures"`
+	Threshold int        `json:"threshold"`
+	CoolDown  string     `json:"cool_down"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"` // earliest half-open probe while open
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// Breaker is a consecutive-failure circuit breaker
+type Breaker struct {
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	threshold int
+	coolDown  time.Duration
+	openedAt  time.Time
+	probing   bool // half-open probe in flight
+	lastError string
+	now       func() time.Time
+	logger    *slog.Logger
+}
+
+// NewBreaker creates a new instance of Breaker struct. A threshold of 0 or less disables the breaker.
+func NewBreaker(threshold int, coolDown time.Duration, logger *slog.Logger) *Breaker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Breaker{
+		threshold: threshold,
+		coolDown:  coolDown,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// Allow reports whether a call can go through. Moves an open breaker to half-open once the cool-down passed.
+func (b *Breaker) Allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		retryAt := b.openedAt.Add(b.coolDown)
+		if b.now().Before(retryAt) {
+			return fmt.Errorf("%w until %s", ErrCircuitOpen, retryAt.UTC().Format(time.RFC3339))
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%w (half-open probe in flight)", ErrCircuitOpen)
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call and closes the breaker.
+func (b *Breaker) Success() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	b.lastError = ""
+	if b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+// Failure records a failed call. Opens the breaker at the threshold or when a half-open probe fails.
+func (b *Breaker) Failure(err error) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.setState(StateOpen)
+	}
+}
+
+// Release frees the half-open probe slot of a call that ended without telling whether the provider is up
+// (cancelled or out of time). The state and failure count are unchanged, the next call probes again.
+func (b *Breaker) Release() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Status returns a snapshot of the breaker.
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{
+		State:     b.state.String(),
+		Failures:  b.failures,
+		Threshold: b.threshold,
+		CoolDown:  b.coolDown.String(),
+		LastError: b.lastError,
+	}
+	if b.state != StateClosed {
+		openedAt, retryAt := b.openedAt, b.openedAt.Add(b.coolDown)
+		status.OpenedAt, status.RetryAt = &openedAt, &retryAt
+	}
+	return status
+}
+
+// setState changes state and logs the transition once. Caller holds b.mu.
+func (b *Breaker) setState(state BreakerState) {
+	from := b.state
+	b.state = state
+	switch state {
+	case StateOpen:
+		b.logger.Error("CMC circuit breaker opened - calls paused",
+			"from", from.String(), "failures", b.failures, "cool_down", b.coolDown, "last_error", b.lastError)
+	case StateHalfOpen:
+		b.logger.Warn("CMC circuit breaker half-open - probing", "from", from.String())
+	case StateClosed:
+		b.logger.Info("CMC circuit breaker closed - calls resumed", "from", from.String())
+	}
+}
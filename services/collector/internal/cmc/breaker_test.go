@@ -0,0 +1,53 @@
+package cmc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestBreakerTransitions tests closed -> open -> half-open -> open/closed transitions
+func TestBreakerTransitions(t *testing.T) {
+	now := time.Date(2026, time.January, 2, 1, 0, 0, 0, time.UTC)
+	b := NewBreaker(2, time.Minute, nil)
+	b.now = func() time.Time { return now }
+	failure := errors.New("boom")
+
+	// Closed until threshold
+	b.Failure(failure)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected closed breaker after 1 failure, got %v", err)
+	}
+	b.Failure(failure)
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected open breaker after 2 failures, got %v", err)
+	}
+
+	// Half-open after cool-down, a single probe allowed
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected probe allowed after cool-down, got %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected second call rejected while probing, got %v", err)
+	}
+	if got := b.Status().State; got != "half-open" {
+		t.Errorf("State = %s, want half-open", got)
+	}
+
+	// Failed probe opens again
+	b.Failure(failure)
+	if got := b.Status().State; got != "open" {
+		t.Errorf("State = %s, want open", got)
+	}
+
+	// Successful probe closes
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected probe allowed after cool-down, got %v", err)
+	}
+	b.Success()
+	if got := b.Status(); got.State != "closed" || got.Failures != 0 {
+		t.Errorf("Status = %+v, want closed with 0 failures", got)
+	}
+}
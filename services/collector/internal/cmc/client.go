@@ -0,0 +1,298 @@
+package cmc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/credits"
+)
+
+// CMC package is the shared HTTP layer for Coinmarketcap API calls made by the ticker and mapper services.
+// Responses are classified (HTTP status and CMC status.error_code), transient failures (429, 1008, 5xx,
+// network errors) are retried with jittered exponential backoff within the context deadline and
+// Retry-After is honored. Credits of every response are recorded through the credit recorder, which also
+// gates the calls that can wait once a credit budget is spent (GetIfBudget).
+
+// Client executes CMC API requests
+type Client struct {
+	apiKey     string
+	http       *http.Client
+	breaker    *Breaker
+	credits    credits.Recorder
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	logger     *slog.Logger
+}
+
+// NewClient creates a new instance of Client struct
+func NewClient(app *config.AppConfig, httpClient *http.Client, creditRecorder credits.Recorder, logger *slog.Logger) *Client {
+	// Validate required dependencies (panic if missing)
+	if app == nil {
+		panic("App configuration required to create CMC Client")
+	}
+	// Validate required dependencies (Warn if missing)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if app.CMC.APIKey == "" {
+		logger.Warn("No API key provided - requires API key")
+	}
+	if httpClient == nil {
+		logger.Warn("No HTTP client provided - using default HTTP client")
+		httpClient = &http.Client{}
+	}
+	if creditRecorder == nil {
+		logger.Warn("No credit recorder provided - API credits will not be tracked")
+	}
+	if app.CMC.BreakerThreshold <= 0 {
+		logger.Warn("Circuit breaker disabled - CMC calls are never paused")
+	}
+	logger.Info("CMC Client initialized successfully",
+		"max_retries", app.CMC.MaxRetries,
+		"breaker_threshold", app.CMC.BreakerThreshold,
+		"breaker_cool_down", app.CMC.BreakerCoolDown)
+
+	return &Client{
+		apiKey:     app.CMC.APIKey,
+		http:       httpClient,
+		breaker:    NewBreaker(app.CMC.BreakerThreshold, app.CMC.BreakerCoolDown, logger),
+		credits:    creditRecorder,
+		maxRetries: max(app.CMC.MaxRetries, 0),
+		baseDelay:  app.CMC.RetryBaseDelay,
+		maxDelay:   app.CMC.RetryMaxDelay,
+		logger:     logger,
+	}
+}
+
+// Get calls a CMC endpoint with query parameters and returns the response body once the response
+// status passed. endpoint names the call for credit accounting and logs (ex. "quotes", "map").
+// Fails fast with ErrCircuitOpen while the circuit breaker is open.
+func (c *Client) Get(ctx context.Context, endpoint, rawURL string, q url.Values) ([]byte, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	body, err := c.getWithRetry(ctx, endpoint, rawURL, q)
+	switch {
+	case err == nil:
+		c.breaker.Success()
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// Cancelled or out of time, tells nothing about the provider
+		c.breaker.Release()
+	case countsAsFailure(err):
+		c.breaker.Failure(err)
+	default:
+		// Request rejected for our own reasons (bad request), the provider is up
+		c.breaker.Success()
+	}
+	return body, err
+}
+
+// GetIfBudget is Get for calls that can wait (ID map, listings): fails fast with credits.ErrBudgetSpent, without
+// calling CMC, once the daily or monthly credit budget is spent.
+func (c *Client) GetIfBudget(ctx context.Context, endpoint, rawURL string, q url.Values) ([]byte, error) {
+	if budget, ok := c.credits.(credits.Budget); ok {
+		if err := budget.Allow(1); err != nil {
+			c.logger.Warn("Skipping CMC request - credit budget spent", "endpoint", endpoint, "error", err)
+			return nil, err
+		}
+	}
+	return c.Get(ctx, endpoint, rawURL, q)
+}
+
+// BreakerStatus returns the circuit breaker state for the status endpoint.
+func (c *Client) BreakerStatus() BreakerStatus {
+	return c.breaker.Status()
+}
+
+// getWithRetry executes a request, retrying transient failures with backoff.
+func (c *Client) getWithRetry(ctx context.Context, endpoint, rawURL string, q url.Values) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.do(ctx, endpoint, rawURL, q)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		// Stop on permanent errors, cancelled contexts and when out of retries
+		if !retryable(ctx, err) || attempt >= c.maxRetries {
+			return nil, lastErr
+		}
+		delay := retryAfter
+		if delay <= 0 {
+			delay = backoff(c.baseDelay, c.maxDelay, attempt)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			c.logger.Warn("Not retrying CMC request - retry delay past context deadline",
+				"endpoint", endpoint, "delay", delay, "error", err)
+			return nil, lastErr
+		}
+
+		c.logger.Warn("Retrying CMC request", "endpoint", endpoint, "attempt", attempt+1, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+	}
+}
+
+// do executes a single request and classifies the response. Returns the Retry-After delay for error responses.
+func (c *Client) do(ctx context.Context, endpoint, rawURL string, q url.Values) ([]byte, time.Duration, error) {
+	// Create new request with context
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Set headers & query parameters
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-CMC_PRO_API_KEY", c.apiKey)
+
+	// Execute request
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "endpoint", endpoint, "error", err, "url", req.URL.String())
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("failed to read response body", "endpoint", endpoint, "error", err)
+		return nil, 0, err
+	}
+
+	// Decode status only (body may not be JSON for some gateway errors)
+	var sr statusResponse
+	jsonErr := json.Unmarshal(body, &sr)
+	if jsonErr == nil && c.credits != nil {
+		c.credits.Record(ctx, endpoint, sr.Status.CreditCount)
+	}
+
+	if resp.StatusCode/100 == 2 && sr.Status.ErrorCode == 0 {
+		if jsonErr != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal response: %w", jsonErr)
+		}
+		c.logger.Info("HTTP request successful", "endpoint", endpoint, "status", resp.Status,
+			"credit_count", sr.Status.CreditCount)
+		return body, 0, nil
+	}
+
+	apiErr := classify(resp, sr.Status)
+	c.logger.Error("Coinmarketcap API returned error",
+		"endpoint", endpoint,
+		"http_status", apiErr.HTTPStatus,
+		"error_code", apiErr.ErrorCode,
+		"error_message", apiErr.Message,
+		"credit_count", sr.Status.CreditCount)
+	return nil, apiErr.RetryAfter, apiErr
+}
+
+// classify builds an APIError from an error response.
+func classify(resp *http.Response, status Status) *APIError {
+	apiErr := &APIError{
+		HTTPStatus: resp.StatusCode,
+		ErrorCode:  status.ErrorCode,
+		Message:    http.StatusText(resp.StatusCode),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+	if status.ErrorMessage != nil {
+		apiErr.Message = *status.ErrorMessage
+	}
+
+	// CMC error codes are more specific than the HTTP status (1009/1010 are sent with HTTP 429)
+	switch {
+	case status.ErrorCode == codePlanUnauthorized,
+		status.ErrorCode == codeDailyRateLimit,
+		status.ErrorCode == codeMonthlyRateLimit:
+		apiErr.Class = ErrPlanLimit
+	case resp.StatusCode == http.StatusTooManyRequests,
+		status.ErrorCode == codeMinuteRateLimit,
+		status.ErrorCode == codeIPRateLimit:
+		apiErr.Class = ErrRateLimited
+		if apiErr.RetryAfter == 0 && status.ErrorCode == codeMinuteRateLimit {
+			apiErr.RetryAfter = untilNextMinute(time.Now()) // minute limit resets on the minute
+		}
+	case resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden,
+		status.ErrorCode >= codeAPIKeyInvalid && status.ErrorCode <= codeAPIKeyDisabled:
+		apiErr.Class = ErrUnauthorized
+	case resp.StatusCode >= 500:
+		apiErr.Class = ErrServer
+	default:
+		apiErr.Class = ErrBadRequest
+	}
+	return apiErr
+}
+
+// retryable reports whether a failed request should be retried.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	// Network errors and truncated bodies
+	return true
+}
+
+// countsAsFailure reports whether an error means the provider is unavailable (trips the breaker).
+// Bad requests don't count. Context errors are handled by Get before.
+func countsAsFailure(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Class != ErrBadRequest
+	}
+	return true
+}
+
+// backoff returns a jittered exponential delay for a retry attempt (0-based): a random delay between
+// half and all of base*2^attempt, capped at maxDelay.
+func backoff(base, maxDelay time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = time.Second
+	}
+	delay := base << min(attempt, 16)
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or HTTP date format. Returns 0 if not set or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// untilNextMinute returns the time left until the next full minute.
+func untilNextMinute(now time.Time) time.Duration {
+	return now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+}
@@ -0,0 +1,155 @@
+package cmc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+)
+
+// TestClientGet tests responses are classified and only transient errors are retried
+func TestClientGet(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []func(w http.ResponseWriter) // one per attempt, last one repeats
+		wantErr      error
+		wantRequests int32
+	}{
+		{
+			name: "429 with Retry-After then success",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"status": {"error_code": 1008, "error_message": "minute limit"}}`))
+				},
+				func(w http.ResponseWriter) {
+					w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 1}, "data": {}}`))
+				},
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "5xx retried until out of retries",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			},
+			wantErr:      ErrServer,
+			wantRequests: 3,
+		},
+		{
+			name: "bad key not retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"status": {"error_code": 1001, "error_message": "This API Key is invalid."}}`))
+				},
+			},
+			wantErr:      ErrUnauthorized,
+			wantRequests: 1,
+		},
+		{
+			name: "monthly limit not retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"status": {"error_code": 1010, "error_message": "monthly limit"}}`))
+				},
+			},
+			wantErr:      ErrPlanLimit,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1))
+				tt.responses[min(n, len(tt.responses))-1](w)
+			}))
+			defer server.Close()
+
+			cfg := &config.AppConfig{
+				CMC: config.CMCSettings{
+					APIKey:         "test-key",
+					MaxRetries:     2,
+					RetryBaseDelay: time.Millisecond,
+					RetryMaxDelay:  5 * time.Millisecond,
+				},
+			}
+			client := NewClient(cfg, server.Client(), nil, nil)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err := client.Get(ctx, "test", server.URL, url.Values{})
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// TestParseRetryAfter tests both Retry-After formats
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, time.January, 2, 1, 0, 0, 0, time.UTC)
+	if got := parseRetryAfter("30", now); got != 30*time.Second {
+		t.Errorf("seconds = %v, want 30s", got)
+	}
+	if got := parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now); got != time.Minute {
+		t.Errorf("HTTP date = %v, want 1m", got)
+	}
+	if got := parseRetryAfter("soon", now); got != 0 {
+		t.Errorf("invalid = %v, want 0", got)
+	}
+}
+
+// TestClientGetCancelledProbe tests a half-open probe ending with a context error neither closes nor reopens the
+// breaker and frees the probe slot
+func TestClientGetCancelledProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": {"error_code": 0}, "data": {}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.AppConfig{CMC: config.CMCSettings{APIKey: "test-key", BreakerThreshold: 1, BreakerCoolDown: time.Minute}}
+	client := NewClient(cfg, server.Client(), nil, nil)
+	now := time.Date(2026, time.January, 2, 1, 0, 0, 0, time.UTC)
+	client.breaker.now = func() time.Time { return now }
+	client.breaker.Failure(errors.New("boom"))
+	now = now.Add(time.Minute)
+
+	for _, ctxErr := range []error{context.Canceled, context.DeadlineExceeded} {
+		ctx, cancel := context.WithCancel(context.Background())
+		if ctxErr == context.DeadlineExceeded {
+			ctx, cancel = context.WithDeadline(context.Background(), now.Add(-time.Hour))
+		}
+		cancel()
+		if _, err := client.Get(ctx, "quotes", server.URL, url.Values{}); !errors.Is(err, ctxErr) {
+			t.Fatalf("Expected %v, got %v", ctxErr, err)
+		}
+		if got := client.BreakerStatus().State; got != "half-open" {
+			t.Errorf("State after %v probe = %s, want half-open", ctxErr, got)
+		}
+	}
+
+	if _, err := client.Get(context.Background(), "quotes", server.URL, url.Values{}); err != nil {
+		t.Fatalf("Expected the next probe allowed, got %v", err)
+	}
+	if got := client.BreakerStatus().State; got != "closed" {
+		t.Errorf("State = %s, want closed", got)
+	}
+}
@@ -0,0 +1,134 @@
+package cmc
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Status holds the response status from CMC API. Every CMC endpoint returns it under the key "status".
+type Status struct {
+	Timestamp    Timestamp `json:"timestamp"`
+	ErrorCode    int       `json:"error_code"`
+	ErrorMessage *string   `json:"error_message"`
+	Elapsed      int       `json:"elapsed"`
+	CreditCount  int       `json:"credit_count"`
+	Notice       *string   `json:"notice"`
+}
+
+// TimeLayout is the CMC timestamp format (ISO 8601 UTC with milliseconds, ex. "2026-01-02T01:24:00.000Z").
+const TimeLayout = "2006-01-02T15:04:05.000Z"
+
+// Timestamp is a CMC timestamp parsed as UTC. null or "" decode to the zero time, stored as NULL.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp returns a Timestamp for a time, converted to UTC.
+func NewTimestamp(t time.Time) Timestamp {
+	if t.IsZero() {
+		return Timestamp{}
+	}
+	return Timestamp{t.UTC()}
+}
+
+// UnmarshalJSON parses an RFC 3339 timestamp (CMC and CoinGecko format) into UTC.
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	s, err := strconv.Unquote(s)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s: %w", b, err)
+	}
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", s, err)
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
+
+// MarshalJSON encodes the timestamp in the CMC format, null for the zero time.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(t.UTC().Format(TimeLayout))), nil
+}
+
+// String returns the timestamp in the CMC format, "" for the zero time.
+func (t Timestamp) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimeLayout)
+}
+
+// Value implements driver.Valuer for TIMESTAMPTZ columns (NULL for the zero time).
+func (t Timestamp) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.UTC(), nil
+}
+
+// statusResponse is used to decode only the status of a response body.
+type statusResponse struct {
+	Status Status `json:"status"`
+}
+
+// CMC status.error_code values (https://coinmarketcap.com/api/documentation/v1/#section/Errors-and-Rate-Limits)
+const (
+	codeAPIKeyInvalid     = 1001
+	codeAPIKeyMissing     = 1002
+	codePlanRequiresPay   = 1003
+	codePlanPaymentExpire = 1004
+	codeAPIKeyRequired    = 1005
+	codePlanUnauthorized  = 1006
+	codeAPIKeyDisabled    = 1007
+	codeMinuteRateLimit   = 1008
+	codeDailyRateLimit    = 1009
+	codeMonthlyRateLimit  = 1010
+	codeIPRateLimit       = 1011
+)
+
+// Error classes for CMC responses, matched with errors.Is on an *APIError.
+var (
+	ErrRateLimited  = errors.New("rate limited")         // HTTP 429, CMC 1008 and 1011, retried
+	ErrUnauthorized = errors.New("unauthorized API key") // HTTP 401/403, CMC 1001-1005 and 1007
+	ErrPlanLimit    = errors.New("plan limit reached")   // CMC 1006, 1009 and 1010, not retried until reset
+	ErrServer       = errors.New("server error")         // HTTP 5xx, retried
+	ErrBadRequest   = errors.New("bad request")          // any other error response
+)
+
+// APIError holds a classified CMC error response.
+type APIError struct {
+	HTTPStatus int           // HTTP status code
+	ErrorCode  int           // CMC status.error_code, 0 if the body had no status
+	Message    string        // CMC status.error_message or HTTP status text
+	RetryAfter time.Duration // parsed Retry-After header, 0 if not set
+	Class      error         // one of ErrRateLimited, ErrUnauthorized, ErrPlanLimit, ErrServer, ErrBadRequest
+}
+
+// Error formats the class, HTTP status and CMC error code.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("CMC API %v (HTTP %d, code %d): %s", e.Class, e.HTTPStatus, e.ErrorCode, e.Message)
+}
+
+// Unwrap returns the error class for errors.Is.
+func (e *APIError) Unwrap() error {
+	return e.Class
+}
+
+// Temporary reports whether the request can be retried.
+func (e *APIError) Temporary() bool {
+	return e.Class == ErrRateLimited || e.Class == ErrServer
+}
@@ -0,0 +1,34 @@
+package cmc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestTimestampUnmarshal tests CMC timestamps are parsed as UTC and null or empty values decode to the zero time
+func TestTimestampUnmarshal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{`"2026-01-02T01:25:55.551Z"`, time.Date(2026, 1, 2, 1, 25, 55, 551000000, time.UTC)},
+		{`"2026-01-02T03:25:55+02:00"`, time.Date(2026, 1, 2, 1, 25, 55, 0, time.UTC)},
+		{`null`, time.Time{}},
+		{`""`, time.Time{}},
+	}
+	for _, tt := range tests {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(tt.input), &ts); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.input, err)
+		}
+		if !ts.Equal(tt.want) || (!ts.IsZero() && ts.Location() != time.UTC) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.input, ts.Time, tt.want)
+		}
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ts); err == nil {
+		t.Error("Expected error for invalid timestamp")
+	}
+}
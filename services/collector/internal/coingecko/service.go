@@ -0,0 +1,291 @@
+package coingecko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/ticker"
+)
+
+// CoinGecko service is a second price provider (ticker.PriceProvider) next to the CMC ticker.
+// Coins are tracked by CMC ID, CoinGecko IDs are looked up in the provider_id_map table (ProviderIDMap).
+// Quotes are fetched from /coins/markets (default) or the lighter /simple/price endpoint, which has
+// no symbol, name, supply, 1h or 7d change.
+
+const (
+	quoteCurrency = "USD"
+	maxIDsPerCall = 250 // /coins/markets per_page maximum
+)
+
+// ErrRateLimited is returned for HTTP 429 responses
+var ErrRateLimited = errors.New("coingecko rate limit reached")
+
+// CoinGeckoService implements ticker.PriceProvider
+type CoinGeckoService struct {
+	apiKey    string
+	keyHeader string
+	baseURL   string
+	endpoint  string        // "markets" or "simple"
+	timeout   time.Duration // per request (COINGECKO_REQUEST_TIMEOUT), 0 = none
+	idMap     ProviderIDMap
+	http      *http.Client
+	logger    *slog.Logger
+}
+
+// NewCoinGeckoService creates a new instance of CoinGeckoService struct.
+func NewCoinGeckoService(app *config.AppConfig, idMap ProviderIDMap, httpClient *http.Client, logger *slog.Logger) *CoinGeckoService {
+	// Validate required dependencies (panic if missing)
+	if app == nil {
+		panic("App configuration required to create CoinGeckoService")
+	}
+	// Validate required dependencies (Warn if missing)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if app.CoinGecko.BaseURL == "" {
+		logger.Warn("No CoinGecko base URL provided - requires base URL")
+	}
+	if app.CoinGecko.APIKey == "" {
+		logger.Warn("No CoinGecko API key provided - using public rate limits")
+	}
+	if idMap == nil {
+		logger.Warn("No provider ID map provided - no coins will be mapped to CoinGecko IDs")
+		idMap = StaticIDMap{}
+	}
+	if httpClient == nil {
+		logger.Warn("No HTTP client provided - using default HTTP client")
+		httpClient = &http.Client{}
+	}
+	endpoint := app.CoinGecko.QuotesEndpoint
+	if endpoint != "markets" && endpoint != "simple" {
+		logger.Warn("Invalid CoinGecko quotes endpoint - using markets", "endpoint", endpoint)
+		endpoint = "markets"
+	}
+
+	// Pro API keys use a different header than demo keys
+	keyHeader := "x-cg-demo-api-key"
+	if strings.Contains(app.CoinGecko.BaseURL, "pro-api") {
+		keyHeader = "x-cg-pro-api-key"
+	}
+
+	logger.Info("CoinGeckoService initialized successfully", "endpoint", endpoint)
+
+	return &CoinGeckoService{
+		apiKey:    app.CoinGecko.APIKey,
+		keyHeader: keyHeader,
+		baseURL:   strings.TrimSuffix(app.CoinGecko.BaseURL, "/"),
+		endpoint:  endpoint,
+		timeout:   max(app.CoinGecko.RequestTimeout, 0),
+		idMap:     idMap,
+		http:      httpClient,
+		logger:    logger,
+	}
+}
+
+// Name returns the provider name.
+func (c *CoinGeckoService) Name() string {
+	return ProviderName
+}
+
+// FetchQuotes gets USD quotes for the CMC IDs. CMC IDs without a CoinGecko ID are skipped (logged).
+// Quotes of successful calls are returned with an error if some calls failed.
+func (c *CoinGeckoService) FetchQuotes(ctx context.Context, cmcIDs []int) ([]ticker.Quote, error) {
+	providerIDs, err := c.idMap.GetProviderIDs(ctx, ProviderName, cmcIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map CMC IDs to CoinGecko IDs: %w", err)
+	}
+	if len(providerIDs) < len(cmcIDs) {
+		c.logger.Warn("CMC IDs without CoinGecko ID skipped", "mapped", len(providerIDs), "requested", len(cmcIDs))
+	}
+	if len(providerIDs) == 0 {
+		return nil, fmt.Errorf("no CoinGecko IDs for %d CMC IDs", len(cmcIDs))
+	}
+
+	// CoinGecko ID -> CMC IDs (keep request order)
+	byProviderID := make(map[string][]int)
+	var ids []string
+	for _, cmcID := range cmcIDs {
+		id, ok := providerIDs[cmcID]
+		if !ok {
+			continue
+		}
+		if _, seen := byProviderID[id]; !seen {
+			ids = append(ids, id)
+		}
+		byProviderID[id] = append(byProviderID[id], cmcID)
+	}
+
+	var quotes []ticker.Quote
+	var errs []error
+	for start := 0; start < len(ids); start += maxIDsPerCall {
+		batch := ids[start:min(start+maxIDsPerCall, len(ids))]
+		var batchQuotes []ticker.Quote
+		var err error
+		if c.endpoint == "simple" {
+			batchQuotes, err = c.fetchSimplePrice(ctx, batch, byProviderID)
+		} else {
+			batchQuotes, err = c.fetchMarkets(ctx, batch, byProviderID)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		quotes = append(quotes, batchQuotes...)
+	}
+	return quotes, errors.Join(errs...)
+}
+
+// fetchMarkets gets quotes for up to 250 CoinGecko IDs from /coins/markets.
+func (c *CoinGeckoService) fetchMarkets(ctx context.Context, ids []string, byProviderID map[string][]int) ([]ticker.Quote, error) {
+	q := url.Values{}
+	q.Add("vs_currency", strings.ToLower(quoteCurrency))
+	q.Add("ids", strings.Join(ids, ","))
+	q.Add("per_page", fmt.Sprint(maxIDsPerCall))
+	q.Add("page", "1")
+	q.Add("price_change_percentage", "1h,24h,7d")
+
+	body, err := c.get(ctx, "/coins/markets", q)
+	if err != nil {
+		return nil, err
+	}
+	var markets []MarketCoin
+	if err := json.Unmarshal(body, &markets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal /coins/markets response: %w", err)
+	}
+
+	var quotes []ticker.Quote
+	for _, m := range markets {
+		for _, cmcID := range byProviderID[m.ID] {
+			quotes = append(quotes, ticker.Quote{
+				Provider:              ProviderName,
+				CmcID:                 cmcID,
+				Symbol:                strings.ToUpper(m.Symbol),
+				Name:                  m.Name,
+				Currency:              quoteCurrency,
+				Price:                 m.CurrentPrice,
+				MarketCap:             m.MarketCap,
+				FullyDilutedMarketCap: m.FullyDilutedValuation,
+				Volume24H:             m.TotalVolume,
+				PercentChange1H:       m.PriceChangePercentage1hInCurrency,
+				PercentChange24h:      m.PriceChangePercentage24hInCurrency,
+				PercentChange7d:       m.PriceChangePercentage7dInCurrency,
+				CirculatingSupply:     m.CirculatingSupply,
+				TotalSupply:           m.TotalSupply,
+				LastUpdated:           m.LastUpdated,
+			})
+		}
+	}
+	return quotes, nil
+}
+
+// fetchSimplePrice gets prices for CoinGecko IDs from /simple/price.
+func (c *CoinGeckoService) fetchSimplePrice(ctx context.Context, ids []string, byProviderID map[string][]int) ([]ticker.Quote, error) {
+	cur := strings.ToLower(quoteCurrency)
+	q := url.Values{}
+	q.Add("ids", strings.Join(ids, ","))
+	q.Add("vs_currencies", cur)
+	q.Add("include_market_cap", "true")
+	q.Add("include_24hr_vol", "true")
+	q.Add("include_24hr_change", "true")
+	q.Add("include_last_updated_at", "true")
+
+	body, err := c.get(ctx, "/simple/price", q)
+	if err != nil {
+		return nil, err
+	}
+	var prices map[string]SimplePrice
+	if err := json.Unmarshal(body, &prices); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal /simple/price response: %w", err)
+	}
+
+	var quotes []ticker.Quote
+	for id, p := range prices {
+		price, ok := p[cur]
+		if !ok {
+			continue
+		}
+		var lastUpdated ticker.Timestamp
+		if ts := int64(p["last_updated_at"].Float64()); ts > 0 {
+			lastUpdated.Time = time.Unix(ts, 0).UTC()
+		}
+		for _, cmcID := range byProviderID[id] {
+			quotes = append(quotes, ticker.Quote{
+				Provider:         ProviderName,
+				CmcID:            cmcID,
+				Currency:         quoteCurrency,
+				Price:            price,
+				MarketCap:        p[cur+"_market_cap"],
+				Volume24H:        p[cur+"_24h_vol"],
+				PercentChange24h: p[cur+"_24h_change"].Float64(),
+				LastUpdated:      lastUpdated,
+			})
+		}
+	}
+	return quotes, nil
+}
+
+// get calls a CoinGecko endpoint and returns the response body for 2xx responses. Each call is bound by the
+// request timeout, on top of the deadline of ctx.
+func (c *CoinGeckoService) get(ctx context.Context, path string, q url.Values) ([]byte, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// Create new request with context
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set headers & query parameters
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set(c.keyHeader, c.apiKey)
+	}
+
+	// Execute request
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "provider", ProviderName, "path", path, "error", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		message := http.StatusText(resp.StatusCode)
+		var errResp ErrorResponse
+		if json.Unmarshal(body, &errResp) == nil {
+			if errResp.Error != "" {
+				message = errResp.Error
+			} else if errResp.Status.ErrorMessage != "" {
+				message = errResp.Status.ErrorMessage
+			}
+		}
+		c.logger.Error("CoinGecko API returned error", "path", path, "http_status", resp.StatusCode, "error_message", message)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, fmt.Errorf("%w: %s", ErrRateLimited, message)
+		}
+		return nil, fmt.Errorf("coingecko %s: HTTP %d: %s", path, resp.StatusCode, message)
+	}
+
+	c.logger.Info("HTTP request successful", "provider", ProviderName, "path", path, "status", resp.Status)
+	return body, nil
+}
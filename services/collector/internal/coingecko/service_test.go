@@ -0,0 +1,116 @@
+package coingecko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/ticker"
+)
+
+// Compile time check CoinGeckoService is a price provider
+var _ ticker.PriceProvider = (*CoinGeckoService)(nil)
+
+func newTestService(t *testing.T, endpoint string, handler http.HandlerFunc) *CoinGeckoService {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	cfg := &config.AppConfig{
+		CoinGecko: config.CoinGeckoSettings{APIKey: "test-key", BaseURL: server.URL, QuotesEndpoint: endpoint},
+	}
+	idMap := StaticIDMap{1: "bitcoin", 1027: "ethereum"}
+	return NewCoinGeckoService(cfg, idMap, server.Client(), nil)
+}
+
+// TestFetchQuotesMarkets tests /coins/markets quotes are mapped back to CMC IDs and unmapped IDs are skipped
+func TestFetchQuotesMarkets(t *testing.T) {
+	svc := newTestService(t, "markets", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coins/markets" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-cg-demo-api-key"); got != "test-key" {
+			t.Errorf("Expected demo API key header, got %q", got)
+		}
+		if got := r.URL.Query().Get("ids"); got != "bitcoin,ethereum" {
+			t.Errorf("Expected ids bitcoin,ethereum, got %q", got)
+		}
+		w.Write([]byte(`[
+			{"id": "bitcoin", "symbol": "btc", "name": "Bitcoin", "current_price": 100000.5, "market_cap": 2000000,
+			 "price_change_percentage_1h_in_currency": 0.5, "total_supply": null, "last_updated": "2026-01-02T01:25:00.000Z"},
+			{"id": "ethereum", "symbol": "eth", "name": "Ethereum", "current_price": 4000}
+		]`))
+	})
+
+	quotes, err := svc.FetchQuotes(context.Background(), []int{1, 1027, 99999})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("Expected 2 quotes, got %d", len(quotes))
+	}
+	btc := quotes[0]
+	if btc.CmcID != 1 || btc.Symbol != "BTC" || btc.Price.String() != "100000.5" || btc.PercentChange1H != 0.5 || btc.Currency != "USD" {
+		t.Errorf("Unexpected bitcoin quote: %+v", btc)
+	}
+	if quotes[1].CmcID != 1027 || quotes[1].Provider != ProviderName {
+		t.Errorf("Unexpected ethereum quote: %+v", quotes[1])
+	}
+}
+
+// TestFetchQuotesSimple tests /simple/price quotes and last_updated_at conversion
+func TestFetchQuotesSimple(t *testing.T) {
+	svc := newTestService(t, "simple", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/simple/price" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"ethereum": {"usd": 4000.25, "usd_24h_change": -1.5, "last_updated_at": 1767317100}}`))
+	})
+
+	quotes, err := svc.FetchQuotes(context.Background(), []int{1027})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(quotes) != 1 {
+		t.Fatalf("Expected 1 quote, got %d", len(quotes))
+	}
+	q := quotes[0]
+	if q.CmcID != 1027 || q.Price.String() != "4000.25" || q.PercentChange24h != -1.5 || q.LastUpdated.String() != "2026-01-02T01:25:00.000Z" {
+		t.Errorf("Unexpected quote: %+v", q)
+	}
+}
+
+// TestFetchQuotesRateLimited tests HTTP 429 returns ErrRateLimited
+func TestFetchQuotesRateLimited(t *testing.T) {
+	svc := newTestService(t, "markets", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"status": {"error_code": 429, "error_message": "You've exceeded the Rate Limit"}}`))
+	})
+
+	_, err := svc.FetchQuotes(context.Background(), []int{1})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+// TestFetchQuotesRequestTimeout tests a call slower than COINGECKO_REQUEST_TIMEOUT fails with a deadline error
+func TestFetchQuotesRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+	cfg := &config.AppConfig{
+		CoinGecko: config.CoinGeckoSettings{BaseURL: server.URL, QuotesEndpoint: "simple", RequestTimeout: 50 * time.Millisecond},
+	}
+	svc := NewCoinGeckoService(cfg, StaticIDMap{1: "bitcoin"}, server.Client(), nil)
+
+	_, err := svc.FetchQuotes(context.Background(), []int{1})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package coingecko
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// SQL statements for the provider_id_map table (db/migrations/007).
+const (
+	selectProviderIDsSQL = `
+		SELECT cmc_id, provider_coin_id FROM provider_id_map
+		WHERE provider = $1 AND cmc_id = ANY(string_to_array($2, ',')::int[])`
+
+	upsertProviderIDSQL = `
+		INSERT INTO provider_id_map (provider, cmc_id, provider_coin_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, cmc_id) DO UPDATE SET
+			provider_coin_id = EXCLUDED.provider_coin_id,
+			updated_at = CURRENT_TIMESTAMP`
+)
+
+// DBIDMap is the provider_id_map table backed ProviderIDMap
+type DBIDMap struct {
+	database *db.Database
+}
+
+// NewDBIDMap creates a ProviderIDMap on database (nil returns db.ErrNotConnected on every call).
+func NewDBIDMap(database *db.Database) *DBIDMap {
+	return &DBIDMap{database: database}
+}
+
+// GetProviderIDs reads the provider IDs of the CMC IDs from provider_id_map. CMC IDs without a row are left out.
+func (m *DBIDMap) GetProviderIDs(ctx context.Context, provider string, cmcIDs []int) (map[int]string, error) {
+	q, err := m.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(cmcIDs))
+	for i, id := range cmcIDs {
+		ids[i] = strconv.Itoa(id)
+	}
+
+	rows, err := q.QueryContext(ctx, selectProviderIDsSQL, provider, strings.Join(ids, ","))
+	if err != nil {
+		return nil, fmt.Errorf("select provider_id_map: %w", err)
+	}
+	defer rows.Close()
+
+	providerIDs := make(map[int]string)
+	for rows.Next() {
+		var cmcID int
+		var providerID string
+		if err := rows.Scan(&cmcID, &providerID); err != nil {
+			return nil, fmt.Errorf("scan provider_id_map: %w", err)
+		}
+		providerIDs[cmcID] = providerID
+	}
+	return providerIDs, rows.Err()
+}
+
+// SetProviderID adds or updates the provider ID of a CMC ID in provider_id_map.
+func (m *DBIDMap) SetProviderID(ctx context.Context, provider string, cmcID int, providerID string) error {
+	q, err := m.database.Querier(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, upsertProviderIDSQL, provider, cmcID, providerID); err != nil {
+		return fmt.Errorf("upsert provider_id_map: %w", err)
+	}
+	return nil
+}
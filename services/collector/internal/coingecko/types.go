@@ -0,0 +1,61 @@
+package coingecko
+
+import (
+	"context"
+
+	"github.com/jdbdev/go-cmc/internal/decimal"
+	"github.com/jdbdev/go-cmc/internal/ticker"
+)
+
+// ProviderName is the name of the CoinGecko provider (provider column of provider_id_map).
+const ProviderName = "coingecko"
+
+// ProviderIDMap maps CMC IDs to a provider's own coin IDs (ex. 1027 -> "ethereum")
+type ProviderIDMap interface {
+	GetProviderIDs(ctx context.Context, provider string, cmcIDs []int) (map[int]string, error)
+}
+
+// StaticIDMap is a fixed CMC ID -> CoinGecko ID map (tests, or running without a DB)
+type StaticIDMap map[int]string
+
+// GetProviderIDs returns the CoinGecko IDs of the CMC IDs found in the map.
+func (s StaticIDMap) GetProviderIDs(ctx context.Context, provider string, cmcIDs []int) (map[int]string, error) {
+	ids := make(map[int]string)
+	for _, cmcID := range cmcIDs {
+		if id, ok := s[cmcID]; ok {
+			ids[cmcID] = id
+		}
+	}
+	return ids, nil
+}
+
+// MarketCoin holds one coin of the /coins/markets response
+// Numbers can be null in the response and are left at 0.
+type MarketCoin struct {
+	ID                                 string           `json:"id"`
+	Symbol                             string           `json:"symbol"`
+	Name                               string           `json:"name"`
+	CurrentPrice                       decimal.Decimal  `json:"current_price"`
+	MarketCap                          decimal.Decimal  `json:"market_cap"`
+	FullyDilutedValuation              decimal.Decimal  `json:"fully_diluted_valuation"`
+	TotalVolume                        decimal.Decimal  `json:"total_volume"`
+	PriceChangePercentage1hInCurrency  float64          `json:"price_change_percentage_1h_in_currency"`
+	PriceChangePercentage24hInCurrency float64          `json:"price_change_percentage_24h_in_currency"`
+	PriceChangePercentage7dInCurrency  float64          `json:"price_change_percentage_7d_in_currency"`
+	CirculatingSupply                  decimal.Decimal  `json:"circulating_supply"`
+	TotalSupply                        decimal.Decimal  `json:"total_supply"`
+	LastUpdated                        ticker.Timestamp `json:"last_updated"`
+}
+
+// SimplePrice holds one coin of the /simple/price response, keyed by field name
+// (ex. "usd", "usd_market_cap", "usd_24h_vol", "usd_24h_change", "last_updated_at").
+type SimplePrice map[string]decimal.Decimal
+
+// ErrorResponse holds the error body returned by CoinGecko for failed requests
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	Status struct {
+		ErrorCode    int    `json:"error_code"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"status"`
+}
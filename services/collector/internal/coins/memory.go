@@ -0,0 +1,127 @@
+package coins
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// MemoryTrackedCoinRepo is an in-memory TrackedCoinRepo for tests and running without a database (USE_DB=false).
+// WithTx doesn't roll back (db.NopTx).
+type MemoryTrackedCoinRepo struct {
+	db.NopTx
+	mu     sync.Mutex
+	coins  map[int]TrackedCoin // by CMC ID
+	nextID int
+}
+
+// NewMemoryTrackedCoinRepo creates a MemoryTrackedCoinRepo holding coins (enabled unless set otherwise).
+func NewMemoryTrackedCoinRepo(coins ...TrackedCoin) *MemoryTrackedCoinRepo {
+	r := &MemoryTrackedCoinRepo{coins: make(map[int]TrackedCoin)}
+	for _, c := range coins {
+		r.nextID++
+		c.ID = r.nextID
+		r.coins[c.CmcID] = c
+	}
+	return r
+}
+
+// TableExists always returns true.
+func (r *MemoryTrackedCoinRepo) TableExists(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// Upsert inserts an enabled coin or refreshes the symbol and name of a tracked coin.
+func (r *MemoryTrackedCoinRepo) Upsert(ctx context.Context, cmcID int, symbol, name string) (*TrackedCoin, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	c, ok := r.coins[cmcID]
+	if !ok {
+		r.nextID++
+		c = TrackedCoin{ID: r.nextID, CmcID: cmcID, Enabled: true, CreatedAt: now}
+	}
+	c.Symbol, c.Name, c.UpdatedAt = symbol, name, now
+	r.coins[cmcID] = c
+	return &c, nil
+}
+
+// SetEnabled sets the enabled flag of a tracked coin.
+func (r *MemoryTrackedCoinRepo) SetEnabled(ctx context.Context, cmcID int, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.coins[cmcID]
+	if !ok {
+		return fmt.Errorf("cmc_id %d: %w", cmcID, ErrCoinNotTracked)
+	}
+	c.Enabled, c.UpdatedAt = enabled, time.Now().UTC()
+	r.coins[cmcID] = c
+	return nil
+}
+
+// Delete removes a tracked coin.
+func (r *MemoryTrackedCoinRepo) Delete(ctx context.Context, cmcID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.coins[cmcID]
+	delete(r.coins, cmcID)
+	return ok, nil
+}
+
+// GetByCMCID returns a tracked coin.
+func (r *MemoryTrackedCoinRepo) GetByCMCID(ctx context.Context, cmcID int) (*TrackedCoin, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.coins[cmcID]
+	if !ok {
+		return nil, fmt.Errorf("cmc_id %d: %w", cmcID, ErrCoinNotTracked)
+	}
+	return &c, nil
+}
+
+// List returns the tracked coins matching the filter, ordered by CMC ID.
+func (r *MemoryTrackedCoinRepo) List(ctx context.Context, filter TrackedCoinFilter) ([]TrackedCoin, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var coins []TrackedCoin
+	for _, c := range r.coins {
+		if filter.Enabled != nil && c.Enabled != *filter.Enabled {
+			continue
+		}
+		if filter.Symbol != "" && !strings.EqualFold(c.Symbol, filter.Symbol) {
+			continue
+		}
+		coins = append(coins, c)
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i].CmcID < coins[j].CmcID })
+
+	coins = coins[min(max(filter.Offset, 0), len(coins)):] // like Postgres, Offset and Limit <= 0 are ignored
+	if filter.Limit > 0 && filter.Limit < len(coins) {
+		coins = coins[:filter.Limit]
+	}
+	return coins, nil
+}
+
+// EnabledIDs returns the CMC IDs of the enabled coins, ordered by CMC ID.
+func (r *MemoryTrackedCoinRepo) EnabledIDs(ctx context.Context) ([]int, error) {
+	enabled := true
+	coins, err := r.List(ctx, TrackedCoinFilter{Enabled: &enabled})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(coins))
+	for i, c := range coins {
+		ids[i] = c.CmcID
+	}
+	return ids, nil
+}
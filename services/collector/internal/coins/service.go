@@ -1,28 +1,226 @@
 package coins
 
-import "log/slog"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
 
+	"github.com/jdbdev/go-cmc/db"
+	"github.com/jdbdev/go-cmc/internal/mapper"
+)
+
+// Coins service manages the tracked_coins table, the source of truth for which coins the ticker fetches.
+// Coins are added by CMC ID and validated through the mapper service before being stored.
+// All writes are idempotent: adding a tracked coin again refreshes it, removing a missing coin is a no-op.
+
+// CoinInterface defines the contract for tracked coin operations
 type CoinInterface interface {
-	InitializeCoinTable() error
-	AddTrackedCoin(symbol string) error
+	InitializeCoinTable(ctx context.Context) error
+	AddTrackedCoin(ctx context.Context, cmcID int, symbol string) (*TrackedCoin, error)
+	AddTrackedCoinBySymbol(ctx context.Context, symbol string, opts mapper.ResolveOptions) (*TrackedCoin, error)
+	SeedTrackedCoins(ctx context.Context, coins []mapper.CmcCoinID) (int, error)
+	EnableCoin(ctx context.Context, cmcID int) error
+	DisableCoin(ctx context.Context, cmcID int) error
+	RemoveTrackedCoin(ctx context.Context, cmcID int) error
+	ListTrackedCoins(ctx context.Context, filter TrackedCoinFilter) ([]TrackedCoin, error)
+	GetTrackedCoinByCMCID(ctx context.Context, cmcID int) (*TrackedCoin, error)
+	GetTrackedCoinsBySymbol(ctx context.Context, symbol string) ([]TrackedCoin, error)
+	GetTrackedCoinIDs(ctx context.Context) ([]int, error)
 }
 
+// CoinService implements the CoinInterface
 type CoinService struct {
+	mapper mapper.IDMapInterface
+	repo   TrackedCoinRepo
 	logger *slog.Logger
 }
 
-func NewCoinService(logger *slog.Logger) *CoinService {
+// NewCoinService creates a new instance of CoinService struct. repo stores the tracked coins (tracked_coins table).
+func NewCoinService(logger *slog.Logger, mapperService mapper.IDMapInterface, repo TrackedCoinRepo) *CoinService {
+	// Validate required dependencies (Warn if missing)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if mapperService == nil {
+		logger.Warn("No mapper service provided - requires mapper service to validate coins")
+	}
+	if repo == nil {
+		logger.Warn("No tracked coin repository provided - requires repository to track coins")
+	}
+	logger.Info("CoinService initialized successfully")
+
 	return &CoinService{
+		mapper: mapperService,
+		repo:   repo,
 		logger: logger,
 	}
 }
 
-func (c *CoinService) InitializeCoinTable() error {
+// InitializeCoinTable checks the tracked_coins table exists (db/migrations/004).
+func (c *CoinService) InitializeCoinTable(ctx context.Context) error {
 	c.logger.Info("Initializing coin table")
+	repo, err := c.repository()
+	if err != nil {
+		return err
+	}
+	exists, err := repo.TableExists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("tracked_coins table does not exist - apply migrations with: migrate up")
+	}
+	return nil
+}
+
+// AddTrackedCoin validates a CMC ID through the mapper service and adds it to tracked_coins.
+// If the coin is already tracked its symbol and name are refreshed and its enabled flag is kept.
+func (c *CoinService) AddTrackedCoin(ctx context.Context, cmcID int, symbol string) (*TrackedCoin, error) {
+	c.logger.Info("Adding coin to table", "cmc_id", cmcID, "symbol", symbol)
+	if c.mapper == nil {
+		return nil, fmt.Errorf("mapper service required to validate CMC ID %d", cmcID)
+	}
+	repo, err := c.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	coin, err := c.mapper.ValidateCMCID(ctx, cmcID, strings.ToUpper(symbol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate CMC ID %d: %w", cmcID, err)
+	}
+	return repo.Upsert(ctx, coin.ID, coin.Symbol, coin.Name)
+}
+
+// AddTrackedCoinBySymbol resolves a symbol to a single CMC asset through the mapper lookup chain and adds it
+// to tracked_coins. Returns a mapper.AmbiguousSymbolError listing the candidates if the symbol can't be
+// resolved, the caller should retry with ResolveOptions.Slug (or AddTrackedCoin with the chosen CMC ID).
+func (c *CoinService) AddTrackedCoinBySymbol(ctx context.Context, symbol string, opts mapper.ResolveOptions) (*TrackedCoin, error) {
+	c.logger.Info("Adding coin to table by symbol", "symbol", symbol, "slug", opts.Slug, "platform", opts.Platform)
+	if c.mapper == nil {
+		return nil, fmt.Errorf("mapper service required to resolve symbol %s", symbol)
+	}
+	repo, err := c.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	coin, source, err := c.mapper.LookupSymbol(ctx, symbol, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve symbol %s: %w", symbol, err)
+	}
+	c.logger.Info("Resolved symbol", "symbol", symbol, "cmc_id", coin.ID, "source", source)
+	return repo.Upsert(ctx, coin.ID, coin.Symbol, coin.Name)
+}
+
+// SeedTrackedCoins adds coins returned by the mapper service (ex. GetCMCTopCoins) to tracked_coins.
+// Entries come from CMC so they are not validated again. All coins are written in one transaction.
+// Returns the number of coins written.
+func (c *CoinService) SeedTrackedCoins(ctx context.Context, coins []mapper.CmcCoinID) (int, error) {
+	repo, err := c.repository()
+	if err != nil {
+		return 0, err
+	}
+	seeded := 0
+	err = repo.WithTx(ctx, func(ctx context.Context) error {
+		for _, coin := range coins {
+			if _, err := repo.Upsert(ctx, coin.ID, coin.Symbol, coin.Name); err != nil {
+				return err
+			}
+			seeded++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	c.logger.Info("Seeded tracked coins", "count", seeded)
+	return seeded, nil
+}
+
+// EnableCoin sets a tracked coin as enabled so the ticker fetches it.
+func (c *CoinService) EnableCoin(ctx context.Context, cmcID int) error {
+	return c.setEnabled(ctx, cmcID, true)
+}
+
+// DisableCoin sets a tracked coin as disabled. The row is kept so it can be enabled again.
+func (c *CoinService) DisableCoin(ctx context.Context, cmcID int) error {
+	return c.setEnabled(ctx, cmcID, false)
+}
+
+// RemoveTrackedCoin deletes a coin from tracked_coins. Removing a coin that is not tracked is a no-op.
+func (c *CoinService) RemoveTrackedCoin(ctx context.Context, cmcID int) error {
+	repo, err := c.repository()
+	if err != nil {
+		return err
+	}
+	removed, err := repo.Delete(ctx, cmcID)
+	if err != nil {
+		return err
+	}
+	c.logger.Info("Removed tracked coin", "cmc_id", cmcID, "removed", removed)
 	return nil
 }
 
-func (c *CoinService) AddTrackedCoin(symbol string) error {
-	c.logger.Info("Adding coin to table", "symbol", symbol)
+// ListTrackedCoins returns tracked coins matching the filter, ordered by CMC ID.
+func (c *CoinService) ListTrackedCoins(ctx context.Context, filter TrackedCoinFilter) ([]TrackedCoin, error) {
+	repo, err := c.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.List(ctx, filter)
+}
+
+// GetTrackedCoinByCMCID returns a tracked coin by CMC ID or ErrCoinNotTracked.
+func (c *CoinService) GetTrackedCoinByCMCID(ctx context.Context, cmcID int) (*TrackedCoin, error) {
+	repo, err := c.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByCMCID(ctx, cmcID)
+}
+
+// GetTrackedCoinsBySymbol returns all tracked coins with a symbol. Symbols are not unique on CMC.
+func (c *CoinService) GetTrackedCoinsBySymbol(ctx context.Context, symbol string) ([]TrackedCoin, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol required")
+	}
+	return c.ListTrackedCoins(ctx, TrackedCoinFilter{Symbol: symbol})
+}
+
+// GetTrackedCoinIDs returns the CMC IDs of all enabled coins in the tracked_coins table.
+// Used by the ticker service to build its quotes query.
+func (c *CoinService) GetTrackedCoinIDs(ctx context.Context) ([]int, error) {
+	repo, err := c.repository()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := repo.EnabledIDs(ctx)
+	if err != nil {
+		c.logger.Error("failed to get tracked coin IDs", "error", err)
+		return nil, err
+	}
+	return ids, nil
+}
+
+// setEnabled updates the enabled flag of a tracked coin.
+func (c *CoinService) setEnabled(ctx context.Context, cmcID int, enabled bool) error {
+	repo, err := c.repository()
+	if err != nil {
+		return err
+	}
+	if err := repo.SetEnabled(ctx, cmcID, enabled); err != nil {
+		return err
+	}
+	c.logger.Info("Updated tracked coin", "cmc_id", cmcID, "enabled", enabled)
 	return nil
 }
+
+// repository returns the tracked coin repository, db.ErrNotConnected if none was provided.
+func (c *CoinService) repository() (TrackedCoinRepo, error) {
+	if c.repo == nil {
+		return nil, db.ErrNotConnected
+	}
+	return c.repo, nil
+}
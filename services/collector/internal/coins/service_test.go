@@ -0,0 +1,91 @@
+package coins
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jdbdev/go-cmc/db"
+	"github.com/jdbdev/go-cmc/internal/mapper"
+)
+
+// TestTrackedCoinLifecycle tests seeding, disabling and removing tracked coins on the in-memory repository
+func TestTrackedCoinLifecycle(t *testing.T) {
+	ctx := context.Background()
+	service := NewCoinService(nil, nil, NewMemoryTrackedCoinRepo())
+
+	seeded, err := service.SeedTrackedCoins(ctx, []mapper.CmcCoinID{
+		{ID: 1027, Symbol: "ETH", Name: "Ethereum"},
+		{ID: 1, Symbol: "BTC", Name: "Bitcoin"},
+		{ID: 20947, Symbol: "SUI", Name: "Sui"},
+	})
+	if err != nil || seeded != 3 {
+		t.Fatalf("Expected 3 coins seeded, got %d (%v)", seeded, err)
+	}
+	if err := service.DisableCoin(ctx, 1027); err != nil {
+		t.Fatalf("DisableCoin: %v", err)
+	}
+	ids, err := service.GetTrackedCoinIDs(ctx)
+	if err != nil || !slices.Equal(ids, []int{1, 20947}) {
+		t.Errorf("Expected enabled IDs [1 20947], got %v (%v)", ids, err)
+	}
+
+	if err := service.RemoveTrackedCoin(ctx, 20947); err != nil {
+		t.Fatalf("RemoveTrackedCoin: %v", err)
+	}
+	if _, err := service.GetTrackedCoinByCMCID(ctx, 20947); !errors.Is(err, ErrCoinNotTracked) {
+		t.Errorf("Expected ErrCoinNotTracked, got %v", err)
+	}
+	if err := service.EnableCoin(ctx, 20947); !errors.Is(err, ErrCoinNotTracked) {
+		t.Errorf("Expected ErrCoinNotTracked enabling a removed coin, got %v", err)
+	}
+
+	coins, err := service.GetTrackedCoinsBySymbol(ctx, "eth")
+	if err != nil || len(coins) != 1 || coins[0].Enabled {
+		t.Errorf("Expected disabled ETH, got %+v (%v)", coins, err)
+	}
+}
+
+// TestCoinServiceNotConnected tests a service without repository returns db.ErrNotConnected
+func TestCoinServiceNotConnected(t *testing.T) {
+	service := NewCoinService(nil, nil, nil)
+	if _, err := service.GetTrackedCoinIDs(context.Background()); !errors.Is(err, db.ErrNotConnected) {
+		t.Errorf("Expected db.ErrNotConnected, got %v", err)
+	}
+}
+
+// TestMemoryListPaging tests Limit and Offset on the in-memory repository, values <= 0 are ignored like Postgres
+func TestMemoryListPaging(t *testing.T) {
+	repo := NewMemoryTrackedCoinRepo(
+		TrackedCoin{CmcID: 1, Symbol: "BTC", Enabled: true},
+		TrackedCoin{CmcID: 52, Symbol: "XRP", Enabled: true},
+		TrackedCoin{CmcID: 1027, Symbol: "ETH", Enabled: true},
+	)
+	tests := []struct {
+		name   string
+		filter TrackedCoinFilter
+		want   []int
+	}{
+		{"all", TrackedCoinFilter{}, []int{1, 52, 1027}},
+		{"limit", TrackedCoinFilter{Limit: 2}, []int{1, 52}},
+		{"offset", TrackedCoinFilter{Offset: 1}, []int{52, 1027}},
+		{"offset past end", TrackedCoinFilter{Offset: 5}, nil},
+		{"negative offset and limit", TrackedCoinFilter{Limit: -1, Offset: -2}, []int{1, 52, 1027}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coins, err := repo.List(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, c := range coins {
+				ids = append(ids, c.CmcID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("List(%+v) = %v, want %v", tt.filter, ids, tt.want)
+			}
+		})
+	}
+}
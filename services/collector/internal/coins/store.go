@@ -0,0 +1,244 @@
+package coins
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// SQL statements for the tracked_coins table (db/migrations/004).
+// Inserts are upserts on cmc_id so seeding the table more than once is safe. The enabled flag
+// of an existing row is never changed by an insert, only by setEnabled.
+const (
+	trackedCoinColumns = `id, cmc_id, symbol, name, enabled, created_at, updated_at`
+
+	tableExistsSQL = `SELECT to_regclass('tracked_coins') IS NOT NULL`
+
+	upsertTrackedCoinSQL = `
+		INSERT INTO tracked_coins (cmc_id, symbol, name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (cmc_id) DO UPDATE SET
+			symbol = EXCLUDED.symbol,
+			name = EXCLUDED.name,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING ` + trackedCoinColumns
+
+	setTrackedCoinEnabledSQL = `
+		UPDATE tracked_coins SET enabled = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE cmc_id = $1`
+
+	deleteTrackedCoinSQL = `DELETE FROM tracked_coins WHERE cmc_id = $1`
+
+	selectTrackedCoinByCMCIDSQL = `SELECT ` + trackedCoinColumns + ` FROM tracked_coins WHERE cmc_id = $1`
+
+	selectTrackedCoinIDsSQL = `SELECT cmc_id FROM tracked_coins WHERE enabled = TRUE ORDER BY cmc_id`
+)
+
+// TrackedCoinRepo stores the tracked coins, injected by NewCoinService.
+type TrackedCoinRepo interface {
+	db.TxRunner
+	// TableExists checks the tracked_coins table exists (migration 004 applied).
+	TableExists(ctx context.Context) (bool, error)
+	// Upsert inserts a coin or refreshes its symbol and name, the enabled flag of a tracked coin is kept.
+	Upsert(ctx context.Context, cmcID int, symbol, name string) (*TrackedCoin, error)
+	// SetEnabled sets the enabled flag of a tracked coin. Returns ErrCoinNotTracked if the coin isn't tracked.
+	SetEnabled(ctx context.Context, cmcID int, enabled bool) error
+	// Delete removes a tracked coin. Returns true if the coin was tracked.
+	Delete(ctx context.Context, cmcID int) (bool, error)
+	// GetByCMCID returns a tracked coin. Returns ErrCoinNotTracked if the coin isn't tracked.
+	GetByCMCID(ctx context.Context, cmcID int) (*TrackedCoin, error)
+	// List returns the tracked coins matching the filter, ordered by CMC ID.
+	List(ctx context.Context, filter TrackedCoinFilter) ([]TrackedCoin, error)
+	// EnabledIDs returns the CMC IDs of the enabled coins, ordered by CMC ID.
+	EnabledIDs(ctx context.Context) ([]int, error)
+}
+
+// PostgresTrackedCoinRepo is the tracked_coins table backed TrackedCoinRepo
+type PostgresTrackedCoinRepo struct {
+	database *db.Database
+}
+
+// NewPostgresTrackedCoinRepo creates a TrackedCoinRepo on database (nil returns db.ErrNotConnected on every call).
+func NewPostgresTrackedCoinRepo(database *db.Database) *PostgresTrackedCoinRepo {
+	return &PostgresTrackedCoinRepo{database: database}
+}
+
+// WithTx runs fn in a transaction shared by the repositories on the same database.
+func (r *PostgresTrackedCoinRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.database.WithTx(ctx, fn)
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTrackedCoin scans a row selected with trackedCoinColumns.
+func scanTrackedCoin(row rowScanner) (TrackedCoin, error) {
+	var c TrackedCoin
+	err := row.Scan(&c.ID, &c.CmcID, &c.Symbol, &c.Name, &c.Enabled, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+// TableExists checks that migration 004 has been applied.
+func (r *PostgresTrackedCoinRepo) TableExists(ctx context.Context) (bool, error) {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return false, err
+	}
+	var exists bool
+	if err := conn.QueryRowContext(ctx, tableExistsSQL).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check tracked_coins table: %w", err)
+	}
+	return exists, nil
+}
+
+// Upsert inserts a coin or refreshes its symbol and name if the cmc_id is already tracked.
+func (r *PostgresTrackedCoinRepo) Upsert(ctx context.Context, cmcID int, symbol, name string) (*TrackedCoin, error) {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := scanTrackedCoin(conn.QueryRowContext(ctx, upsertTrackedCoinSQL, cmcID, symbol, name))
+	if err != nil {
+		return nil, fmt.Errorf("upsert tracked_coins (cmc_id %d): %w", cmcID, err)
+	}
+	return &c, nil
+}
+
+// SetEnabled sets the enabled flag for a tracked coin. Returns ErrCoinNotTracked if no row matches.
+func (r *PostgresTrackedCoinRepo) SetEnabled(ctx context.Context, cmcID int, enabled bool) error {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := conn.ExecContext(ctx, setTrackedCoinEnabledSQL, cmcID, enabled)
+	if err != nil {
+		return fmt.Errorf("update tracked_coins (cmc_id %d): %w", cmcID, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("cmc_id %d: %w", cmcID, ErrCoinNotTracked)
+	}
+	return nil
+}
+
+// Delete removes a tracked coin. Returns true if a row was deleted.
+func (r *PostgresTrackedCoinRepo) Delete(ctx context.Context, cmcID int) (bool, error) {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return false, err
+	}
+	res, err := conn.ExecContext(ctx, deleteTrackedCoinSQL, cmcID)
+	if err != nil {
+		return false, fmt.Errorf("delete tracked_coins (cmc_id %d): %w", cmcID, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetByCMCID reads a single tracked coin. Returns ErrCoinNotTracked if no row matches.
+func (r *PostgresTrackedCoinRepo) GetByCMCID(ctx context.Context, cmcID int) (*TrackedCoin, error) {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := scanTrackedCoin(conn.QueryRowContext(ctx, selectTrackedCoinByCMCIDSQL, cmcID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("cmc_id %d: %w", cmcID, ErrCoinNotTracked)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select tracked_coins (cmc_id %d): %w", cmcID, err)
+	}
+	return &c, nil
+}
+
+// List reads tracked coins matching the filter, ordered by cmc_id.
+func (r *PostgresTrackedCoinRepo) List(ctx context.Context, filter TrackedCoinFilter) ([]TrackedCoin, error) {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		where []string
+		args  []any
+	)
+	if filter.Enabled != nil {
+		args = append(args, *filter.Enabled)
+		where = append(where, "enabled = $"+strconv.Itoa(len(args)))
+	}
+	if filter.Symbol != "" {
+		args = append(args, filter.Symbol)
+		where = append(where, "UPPER(symbol) = UPPER($"+strconv.Itoa(len(args))+")")
+	}
+
+	query := `SELECT ` + trackedCoinColumns + ` FROM tracked_coins`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY cmc_id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += ` OFFSET $` + strconv.Itoa(len(args))
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select tracked_coins: %w", err)
+	}
+	defer rows.Close()
+
+	var coins []TrackedCoin
+	for rows.Next() {
+		c, err := scanTrackedCoin(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan tracked_coins row: %w", err)
+		}
+		coins = append(coins, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracked_coins rows: %w", err)
+	}
+	return coins, nil
+}
+
+// EnabledIDs reads the CMC IDs of all enabled tracked coins.
+func (r *PostgresTrackedCoinRepo) EnabledIDs(ctx context.Context) ([]int, error) {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, selectTrackedCoinIDsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("select tracked_coins ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan tracked_coins id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracked_coins ids: %w", err)
+	}
+	return ids, nil
+}
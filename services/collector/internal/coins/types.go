@@ -1,13 +1,28 @@
 package coins
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrCoinNotTracked is returned when a CMC ID has no row in the tracked_coins table.
+var ErrCoinNotTracked = errors.New("coin not tracked")
 
 // TrackedCoin stores the info for a tracked coin. Row in DB tracked_coins table.
 type TrackedCoin struct {
 	ID        int // primary key
-	CmCID     int // Coinmarketcap ID
+	CmcID     int // Coinmarketcap ID
 	Symbol    string
 	Name      string
-	Enableed  bool      // coin istracked or not
+	Enabled   bool      // coin is tracked or not
 	CreatedAt time.Time // initial creation date in DB table
+	UpdatedAt time.Time // last change to the row (name/symbol refresh, enabled toggle)
+}
+
+// TrackedCoinFilter holds the optional filters for ListTrackedCoins. Zero values are ignored.
+type TrackedCoinFilter struct {
+	Enabled *bool  // only enabled (true) or disabled (false) coins
+	Symbol  string // exact symbol match (case insensitive)
+	Limit   int
+	Offset  int
 }
@@ -0,0 +1,257 @@
+package credits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// Credits service keeps track of Coinmarketcap API credits (status.credit_count of every response).
+// Each call is saved to the api_credit_usage table and added to in-memory daily and monthly totals.
+// NextInterval stretches the ticker interval when the projected spend at the current interval would
+// go over the daily or monthly budget, and Allow refuses calls that can wait once a budget is spent. Days and
+// months are counted in UTC (CMC daily limits reset at 00:00 UTC).
+
+// Accountant implements the Recorder interface and enforces the credit budgets
+type Accountant struct {
+	mu            sync.Mutex
+	dailyBudget   int           // 0 = unlimited
+	monthlyBudget int           // 0 = unlimited
+	interval      time.Duration // last interval returned by NextInterval
+	dayStart      time.Time
+	monthStart    time.Time
+	usedToday     int
+	usedMonth     int
+	recent        []Usage   // calls in the last hour for the burn rate
+	repo          UsageRepo // nil = totals are kept in memory only
+	now           func() time.Time
+	logger        *slog.Logger
+}
+
+// NewAccountant creates a new instance of Accountant struct. Usage is saved to repo, nil keeps totals in memory only.
+func NewAccountant(app *config.AppConfig, repo UsageRepo, logger *slog.Logger) *Accountant {
+	// Validate required dependencies (panic if missing)
+	if app == nil {
+		panic("App configuration required to create Accountant")
+	}
+	// Validate required dependencies (Warn if missing)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if app.CMC.DailyCreditBudget <= 0 && app.CMC.MonthlyCreditBudget <= 0 {
+		logger.Warn("No credit budget set - ticker interval will not be adjusted")
+	}
+	logger.Info("Credit Accountant initialized successfully",
+		"daily_budget", app.CMC.DailyCreditBudget,
+		"monthly_budget", app.CMC.MonthlyCreditBudget)
+
+	a := &Accountant{
+		dailyBudget:   max(app.CMC.DailyCreditBudget, 0),
+		monthlyBudget: max(app.CMC.MonthlyCreditBudget, 0),
+		interval:      app.Interval.TickerInterval,
+		repo:          repo,
+		now:           func() time.Time { return time.Now().UTC() },
+		logger:        logger,
+	}
+	a.dayStart, a.monthStart = periodStarts(a.now())
+	return a
+}
+
+// Load restores today's and this month's totals from the api_credit_usage table. No-op without a database.
+// Called at startup and when the database recovers: totals counted in memory during an outage (not saved)
+// are kept if higher than the saved ones.
+func (a *Accountant) Load(ctx context.Context) error {
+	if a.repo == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	a.rollover(now)
+
+	usedMonth, err := a.repo.SumSince(ctx, a.monthStart)
+	if errors.Is(err, db.ErrNotConnected) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	usedToday, err := a.repo.SumSince(ctx, a.dayStart)
+	if err != nil {
+		return err
+	}
+	recent, err := a.repo.ListSince(ctx, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	a.usedMonth, a.usedToday = max(a.usedMonth, usedMonth), max(a.usedToday, usedToday)
+	if len(recent) > len(a.recent) {
+		a.recent = recent
+	}
+
+	a.logger.Info("Credit usage loaded", "used_today", a.usedToday, "used_month", a.usedMonth)
+	return nil
+}
+
+// Record adds the credits used by a CMC call to the totals and saves it to the DB (if connected).
+func (a *Accountant) Record(ctx context.Context, endpoint string, credits int) {
+	if credits <= 0 {
+		return
+	}
+	u := Usage{Endpoint: endpoint, Credits: credits, RecordedAt: a.now()}
+
+	a.mu.Lock()
+	a.rollover(u.RecordedAt)
+	a.usedToday += credits
+	a.usedMonth += credits
+	a.recent = append(a.recent, u)
+	a.mu.Unlock()
+
+	if a.repo != nil {
+		if err := a.repo.Insert(ctx, u); err != nil && !errors.Is(err, db.ErrNotConnected) {
+			a.logger.Error("failed to save credit usage", "error", err, "endpoint", endpoint, "credits", credits)
+		}
+	}
+}
+
+// Allow returns ErrBudgetSpent if a call costing cost credits would go over the daily or monthly budget.
+func (a *Accountant) Allow(cost int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rollover(a.now())
+
+	if a.dailyBudget > 0 && a.usedToday+cost > a.dailyBudget {
+		return fmt.Errorf("%w: %d of %d daily credits used", ErrBudgetSpent, a.usedToday, a.dailyBudget)
+	}
+	if a.monthlyBudget > 0 && a.usedMonth+cost > a.monthlyBudget {
+		return fmt.Errorf("%w: %d of %d monthly credits used", ErrBudgetSpent, a.usedMonth, a.monthlyBudget)
+	}
+	return nil
+}
+
+// NextInterval returns the ticker interval to use after a tick that cost tickCredits.
+// Returns base unless the projected spend at base would pass a budget, then the shortest interval
+// that keeps the remaining ticks of the day (and month) within budget. If a budget is already
+// spent the interval lasts until the budget resets.
+func (a *Accountant) NextInterval(base time.Duration, tickCredits int) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	a.rollover(now)
+
+	next := base
+	if tickCredits > 0 {
+		dayEnd := a.dayStart.AddDate(0, 0, 1)
+		monthEnd := a.monthStart.AddDate(0, 1, 0)
+		next = max(next,
+			requiredInterval(a.dailyBudget, a.usedToday, tickCredits, dayEnd.Sub(now)),
+			requiredInterval(a.monthlyBudget, a.usedMonth, tickCredits, monthEnd.Sub(now)))
+	}
+
+	// Log when the interval starts or stops being stretched, not on every small adjustment
+	if (next > base) != (a.interval > base) {
+		a.logger.Info("Ticker interval adjusted for credit budget",
+			"interval", next, "base", base,
+			"used_today", a.usedToday, "daily_budget", a.dailyBudget,
+			"used_month", a.usedMonth, "monthly_budget", a.monthlyBudget)
+	}
+	a.interval = next
+	return next
+}
+
+// BurnRate returns the current credit spend and the projected spend at the current interval.
+func (a *Accountant) BurnRate() BurnRate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	a.rollover(now)
+
+	// Drop calls older than an hour
+	cutoff := now.Add(-time.Hour)
+	kept := a.recent[:0]
+	lastHour := 0
+	for _, u := range a.recent {
+		if u.RecordedAt.After(cutoff) {
+			kept = append(kept, u)
+			lastHour += u.Credits
+		}
+	}
+	a.recent = kept
+
+	rate := BurnRate{
+		LastHour:      lastHour,
+		UsedToday:     a.usedToday,
+		UsedMonth:     a.usedMonth,
+		DailyBudget:   a.dailyBudget,
+		MonthlyBudget: a.monthlyBudget,
+	}
+	if elapsed := now.Sub(a.dayStart).Hours(); elapsed > 0 {
+		rate.PerHour = float64(a.usedToday) / elapsed
+	}
+
+	// Project the last hour's spend over the rest of the day and month
+	perHour := float64(lastHour)
+	rate.ProjectedDaily = a.usedToday + int(perHour*a.dayStart.AddDate(0, 0, 1).Sub(now).Hours())
+	rate.ProjectedMonthly = a.usedMonth + int(perHour*a.monthStart.AddDate(0, 1, 0).Sub(now).Hours())
+	return rate
+}
+
+// QuotesCallCost returns the CMC credits of a quotes call: 1 credit per 100 coins (rounded up)
+// plus 1 credit per convert currency past the first.
+func QuotesCallCost(coins, currencies int) int {
+	if coins <= 0 {
+		return 0
+	}
+	return (coins+99)/100 + max(currencies-1, 0)
+}
+
+// ListingsCallCost returns the CMC credits of a listings call: 1 credit per 200 coins (rounded up)
+// plus 1 credit per convert currency past the first.
+func ListingsCallCost(coins, currencies int) int {
+	if coins <= 0 {
+		return 0
+	}
+	return (coins+199)/200 + max(currencies-1, 0)
+}
+
+// rollover resets the daily and monthly totals when a new UTC day or month starts. Caller holds a.mu.
+func (a *Accountant) rollover(now time.Time) {
+	dayStart, monthStart := periodStarts(now)
+	if dayStart.After(a.dayStart) {
+		a.dayStart, a.usedToday = dayStart, 0
+	}
+	if monthStart.After(a.monthStart) {
+		a.monthStart, a.usedMonth = monthStart, 0
+	}
+}
+
+// periodStarts returns the start of the UTC day and month for a time.
+func periodStarts(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return dayStart, monthStart
+}
+
+// requiredInterval returns the shortest interval between ticks costing tickCredits so the spend
+// until the end of the period (remaining) stays within budget. Returns 0 for an unlimited budget.
+func requiredInterval(budget, used, tickCredits int, remaining time.Duration) time.Duration {
+	if budget <= 0 || remaining <= 0 {
+		return 0
+	}
+	left := budget - used
+	if left < tickCredits {
+		// Budget spent, wait until it resets
+		return remaining
+	}
+	ticks := math.Floor(float64(left) / float64(tickCredits))
+	return time.Duration(float64(remaining) / ticks)
+}
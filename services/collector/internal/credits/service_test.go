@@ -0,0 +1,171 @@
+package credits
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+)
+
+// newTestAccountant returns an Accountant with a fixed clock at 12:00 UTC on the 16th of a 30 day month
+func newTestAccountant(daily, monthly int) *Accountant {
+	app := &config.AppConfig{
+		CMC:      config.CMCSettings{DailyCreditBudget: daily, MonthlyCreditBudget: monthly},
+		Interval: config.IntervalSettings{TickerInterval: 2 * time.Minute},
+	}
+	a := NewAccountant(app, nil, nil)
+	now := time.Date(2026, time.June, 16, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+	a.dayStart, a.monthStart = periodStarts(now)
+	return a
+}
+
+// TestNextInterval tests the ticker interval is only stretched when a budget would be passed
+func TestNextInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		daily   int
+		monthly int
+		used    int
+		want    time.Duration
+	}{
+		{"unlimited", 0, 0, 0, 2 * time.Minute},
+		{"within daily budget", 1000, 0, 0, 2 * time.Minute},      // 360 ticks left today
+		{"daily budget stretches", 120, 0, 0, 6 * time.Minute},    // 120 credits over 12h
+		{"daily budget spent", 100, 0, 100, 12 * time.Hour},       // wait until 00:00 UTC
+		{"monthly budget stretches", 0, 360, 0, 58 * time.Minute}, // 360 credits over 14.5 days
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAccountant(tt.daily, tt.monthly)
+			a.usedToday, a.usedMonth = tt.used, tt.used
+
+			got := a.NextInterval(2*time.Minute, 1)
+			if got != tt.want {
+				t.Errorf("NextInterval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAllow tests calls are refused once the daily or monthly budget is spent
+func TestAllow(t *testing.T) {
+	a := newTestAccountant(10, 15)
+	a.Record(context.Background(), "quotes", 9)
+	if err := a.Allow(1); err != nil {
+		t.Errorf("Allow(1) with 1 daily credit left = %v, want nil", err)
+	}
+	if err := a.Allow(2); !errors.Is(err, ErrBudgetSpent) {
+		t.Errorf("Allow(2) with 1 daily credit left = %v, want ErrBudgetSpent", err)
+	}
+
+	// Next day: daily total resets, 6 monthly credits left
+	next := a.now().Add(24 * time.Hour)
+	a.now = func() time.Time { return next }
+	if err := a.Allow(6); err != nil {
+		t.Errorf("Allow(6) the next day = %v, want nil", err)
+	}
+	if err := a.Allow(7); !errors.Is(err, ErrBudgetSpent) {
+		t.Errorf("Allow(7) with 6 monthly credits left = %v, want ErrBudgetSpent", err)
+	}
+	if err := newTestAccountant(0, 0).Allow(1000); err != nil {
+		t.Errorf("Allow without budgets = %v, want nil", err)
+	}
+}
+
+// usageRepo is an in-memory UsageRepo failing every call while down
+type usageRepo struct {
+	down  bool
+	saved []Usage
+}
+
+func (r *usageRepo) Insert(ctx context.Context, u Usage) error {
+	if r.down {
+		return errors.New("connection refused")
+	}
+	r.saved = append(r.saved, u)
+	return nil
+}
+
+func (r *usageRepo) SumSince(ctx context.Context, since time.Time) (int, error) {
+	if r.down {
+		return 0, errors.New("connection refused")
+	}
+	sum := 0
+	for _, u := range r.saved {
+		if !u.RecordedAt.Before(since) {
+			sum += u.Credits
+		}
+	}
+	return sum, nil
+}
+
+func (r *usageRepo) ListSince(ctx context.Context, since time.Time) ([]Usage, error) {
+	if r.down {
+		return nil, errors.New("connection refused")
+	}
+	return r.saved, nil
+}
+
+// TestLoadAfterOutage tests usage saved before a start during an outage is loaded once the database recovers
+func TestLoadAfterOutage(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAccountant(100, 0)
+	saved := Usage{Endpoint: "quotes", Credits: 95, RecordedAt: a.now().Add(-time.Hour)}
+	repo := &usageRepo{down: true, saved: []Usage{saved}}
+	a.repo = repo
+
+	if err := a.Load(ctx); err == nil {
+		t.Fatal("Expected Load to fail while the database is down")
+	}
+	a.Record(ctx, "quotes", 3) // not saved
+	if err := a.Allow(10); err != nil {
+		t.Fatalf("Allow before recovery = %v, want nil (saved usage unknown)", err)
+	}
+
+	repo.down = false
+	if err := a.Load(ctx); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if rate := a.BurnRate(); rate.UsedToday != 95 {
+		t.Errorf("UsedToday = %d, want 95", rate.UsedToday)
+	}
+	if err := a.Allow(10); !errors.Is(err, ErrBudgetSpent) {
+		t.Errorf("Allow after recovery = %v, want ErrBudgetSpent", err)
+	}
+}
+
+// TestBurnRate tests recorded credits are reported in the burn rate
+func TestBurnRate(t *testing.T) {
+	a := newTestAccountant(0, 0)
+	a.Record(context.Background(), "quotes", 3)
+	a.Record(context.Background(), "map", 2)
+
+	rate := a.BurnRate()
+	if rate.LastHour != 5 || rate.UsedToday != 5 || rate.UsedMonth != 5 {
+		t.Errorf("BurnRate = %+v, want 5 credits last hour, today and month", rate)
+	}
+	if rate.ProjectedDaily != 5+5*12 {
+		t.Errorf("ProjectedDaily = %d, want %d", rate.ProjectedDaily, 5+5*12)
+	}
+}
+
+// TestQuotesCallCost tests credits per 100 coins plus extra convert currencies
+func TestQuotesCallCost(t *testing.T) {
+	tests := []struct{ coins, currencies, want int }{
+		{0, 1, 0},
+		{1, 1, 1},
+		{100, 1, 1},
+		{101, 1, 2},
+		{100, 4, 4},
+		{250, 2, 4},
+	}
+	for _, tt := range tests {
+		if got := QuotesCallCost(tt.coins, tt.currencies); got != tt.want {
+			t.Errorf("QuotesCallCost(%d, %d) = %d, want %d", tt.coins, tt.currencies, got, tt.want)
+		}
+	}
+}
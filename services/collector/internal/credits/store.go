@@ -0,0 +1,88 @@
+package credits
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// SQL statements for the api_credit_usage table (db/migrations/006).
+const (
+	insertUsageSQL = `INSERT INTO api_credit_usage (endpoint, credits, recorded_at) VALUES ($1, $2, $3)`
+
+	sumUsageSinceSQL = `SELECT COALESCE(SUM(credits), 0) FROM api_credit_usage WHERE recorded_at >= $1`
+
+	selectUsageSinceSQL = `
+		SELECT endpoint, credits, recorded_at FROM api_credit_usage
+		WHERE recorded_at >= $1 ORDER BY recorded_at`
+)
+
+// UsageRepo stores the credits used by CMC calls, injected by NewAccountant.
+type UsageRepo interface {
+	Insert(ctx context.Context, u Usage) error
+	SumSince(ctx context.Context, since time.Time) (int, error)
+	ListSince(ctx context.Context, since time.Time) ([]Usage, error)
+}
+
+// PostgresUsageRepo is the api_credit_usage table backed UsageRepo
+type PostgresUsageRepo struct {
+	database *db.Database
+}
+
+// NewPostgresUsageRepo creates a UsageRepo on database (nil returns db.ErrNotConnected on every call).
+func NewPostgresUsageRepo(database *db.Database) *PostgresUsageRepo {
+	return &PostgresUsageRepo{database: database}
+}
+
+// Insert stores the credits used by a CMC call.
+func (r *PostgresUsageRepo) Insert(ctx context.Context, u Usage) error {
+	q, err := r.database.Querier(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, insertUsageSQL, u.Endpoint, u.Credits, u.RecordedAt); err != nil {
+		return fmt.Errorf("insert api_credit_usage: %w", err)
+	}
+	return nil
+}
+
+// SumSince returns the credits used since a point in time.
+func (r *PostgresUsageRepo) SumSince(ctx context.Context, since time.Time) (int, error) {
+	q, err := r.database.Querier(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var total int
+	if err := q.QueryRowContext(ctx, sumUsageSinceSQL, since).Scan(&total); err != nil {
+		return 0, fmt.Errorf("sum api_credit_usage: %w", err)
+	}
+	return total, nil
+}
+
+// ListSince reads the calls recorded since a point in time, oldest first.
+func (r *PostgresUsageRepo) ListSince(ctx context.Context, since time.Time) ([]Usage, error) {
+	q, err := r.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, selectUsageSinceSQL, since)
+	if err != nil {
+		return nil, fmt.Errorf("select api_credit_usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []Usage
+	for rows.Next() {
+		var u Usage
+		if err := rows.Scan(&u.Endpoint, &u.Credits, &u.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan api_credit_usage row: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate api_credit_usage rows: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,40 @@
+package credits
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBudgetSpent is returned by Allow when a call would go over the daily or monthly credit budget.
+var ErrBudgetSpent = errors.New("CMC credit budget spent")
+
+// Recorder records API credits used by a CMC call. Implemented by Accountant, used by the ticker and mapper services.
+type Recorder interface {
+	Record(ctx context.Context, endpoint string, credits int)
+}
+
+// Budget reports whether a call fits in the remaining credit budgets. Implemented by Accountant, used by the CMC
+// client to skip calls that can wait (ex. ID map sync) once a budget is spent.
+type Budget interface {
+	Allow(cost int) error
+}
+
+// Usage stores the credits used by a single CMC API call. Row in DB api_credit_usage table.
+type Usage struct {
+	Endpoint   string // CMC endpoint called (ex. "quotes", "map")
+	Credits    int    // status.credit_count from the CMC response
+	RecordedAt time.Time
+}
+
+// BurnRate holds the current credit spend against the configured budgets. Budgets of 0 are unlimited.
+type BurnRate struct {
+	LastHour         int     `json:"last_hour"`         // credits used in the last hour
+	PerHour          float64 `json:"per_hour"`          // average credits per hour since the start of the UTC day
+	UsedToday        int     `json:"used_today"`        // credits used since 00:00 UTC
+	UsedMonth        int     `json:"used_month"`        // credits used since the 1st of the month (UTC)
+	DailyBudget      int     `json:"daily_budget"`      // CMC_DAILY_CREDIT_BUDGET
+	MonthlyBudget    int     `json:"monthly_budget"`    // CMC_MONTHLY_CREDIT_BUDGET
+	ProjectedDaily   int     `json:"projected_daily"`   // projected credits at end of day at the current interval
+	ProjectedMonthly int     `json:"projected_monthly"` // projected credits at end of month at the current interval
+}
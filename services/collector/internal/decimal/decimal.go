@@ -0,0 +1,197 @@
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Decimal package keeps prices and supplies exact from JSON decoding to DB writes.
+// A Decimal holds the plain decimal text of a number (ex. "0.000000001234", "589247000000000"), exponents
+// in the source (ex. 1.234e-9) are expanded. Values are written to NUMERIC columns as text, so no precision
+// is lost in float64. Use Fits to check a value against a NUMERIC(precision, scale) column before writing.
+
+// ErrOutOfRange is returned by Fits when a value has more integer digits than the column allows
+var ErrOutOfRange = errors.New("decimal out of range")
+
+// maxExponent limits exponent expansion (ex. 1e1000000) to keep values a sane size.
+const maxExponent = 400
+
+// Decimal is an exact decimal number. The zero value is 0.
+type Decimal struct {
+	s string // normalized plain decimal, "" = 0
+}
+
+// New parses a decimal number, exponent notation included (ex. "88615.15", "-1.5e-9").
+func New(s string) (Decimal, error) {
+	n, err := normalize(s)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{s: n}, nil
+}
+
+// FromFloat returns the shortest decimal representation of a float64.
+func FromFloat(f float64) Decimal {
+	d, _ := New(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+// String returns the plain decimal text ("0" for zero).
+func (d Decimal) String() string {
+	if d.s == "" {
+		return "0"
+	}
+	return d.s
+}
+
+// Float64 returns the nearest float64, for calculations where exactness doesn't matter (ex. deviations).
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero reports whether the value is 0.
+func (d Decimal) IsZero() bool {
+	return d.s == "" || d.s == "0"
+}
+
+// Sign returns -1, 0 or 1.
+func (d Decimal) Sign() int {
+	switch {
+	case d.IsZero():
+		return 0
+	case d.s[0] == '-':
+		return -1
+	}
+	return 1
+}
+
+// IntegerDigits returns the number of digits before the decimal point (0 for values below 1).
+func (d Decimal) IntegerDigits() int {
+	intPart, _, _ := strings.Cut(strings.TrimPrefix(d.String(), "-"), ".")
+	if intPart == "0" {
+		return 0
+	}
+	return len(intPart)
+}
+
+// Fits returns ErrOutOfRange if the value doesn't fit a NUMERIC(precision, scale) column.
+// Extra fraction digits are rounded by Postgres and are not an error.
+func (d Decimal) Fits(precision, scale int) error {
+	if allowed := precision - scale; d.IntegerDigits() > allowed {
+		return fmt.Errorf("%w: %s has %d integer digits, NUMERIC(%d, %d) allows %d",
+			ErrOutOfRange, d.String(), d.IntegerDigits(), precision, scale, allowed)
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a JSON number or numeric string. null leaves the value unchanged.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	n, err := normalize(s)
+	if err != nil {
+		return err
+	}
+	d.s = n
+	return nil
+}
+
+// MarshalJSON encodes the value as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Value implements driver.Valuer, the value is sent as text and cast by Postgres to NUMERIC.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns (NULL scans as 0, use *Decimal for nullable columns).
+func (d *Decimal) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		d.s = ""
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		*d = FromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into decimal", src)
+	}
+	n, err := normalize(s)
+	if err != nil {
+		return err
+	}
+	d.s = n
+	return nil
+}
+
+// normalize converts a number in plain or exponent notation to plain decimal text without
+// leading or trailing zeros (ex. "001.2300" -> "1.23", "1.5e-3" -> "0.0015", "-0" -> "0").
+func normalize(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	orig := s
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	// Split exponent
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxExponent || e < -maxExponent {
+			return "", fmt.Errorf("invalid decimal %q", orig)
+		}
+		exp, s = e, s[:i]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("invalid decimal %q", orig)
+	}
+
+	// Move the decimal point by the exponent
+	point := len(intPart) + exp
+	switch {
+	case point <= 0:
+		intPart, fracPart = "0", strings.Repeat("0", -point)+digits
+	case point >= len(digits):
+		intPart, fracPart = digits+strings.Repeat("0", point-len(digits)), ""
+	default:
+		intPart, fracPart = digits[:point], digits[point:]
+	}
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	n := intPart
+	if fracPart != "" {
+		n += "." + fracPart
+	}
+	if neg && n != "0" {
+		n = "-" + n
+	}
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestNew tests plain and exponent notation are normalized exactly
+func TestNew(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"88615.1532707756", "88615.1532707756"},
+		{"0.000000001234", "0.000000001234"},
+		{"1.234e-9", "0.000000001234"},
+		{"1.5E3", "1500"},
+		{"589247000000000", "589247000000000"},
+		{"5.89247e14", "589247000000000"},
+		{"001.2300", "1.23"},
+		{"-0.0", "0"},
+		{"-42", "-42"},
+		{".5", "0.5"},
+	}
+	for _, tt := range tests {
+		d, err := New(tt.in)
+		if err != nil {
+			t.Errorf("New(%q) error: %v", tt.in, err)
+			continue
+		}
+		if d.String() != tt.want {
+			t.Errorf("New(%q) = %s, want %s", tt.in, d, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "abc", "1.2.3", "1e", "1e999999", "--1"} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("New(%q) expected error", bad)
+		}
+	}
+}
+
+// TestUnmarshalJSON tests numbers, strings and null decode
+func TestUnmarshalJSON(t *testing.T) {
+	var v struct {
+		Price  Decimal  `json:"price"`
+		Supply *Decimal `json:"supply"`
+		Max    *Decimal `json:"max"`
+	}
+	body := `{"price": 1.234e-9, "supply": "999999999999999999.5", "max": null}`
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v.Price.String() != "0.000000001234" {
+		t.Errorf("Expected exact price, got %s", v.Price)
+	}
+	if v.Supply == nil || v.Supply.String() != "999999999999999999.5" {
+		t.Errorf("Expected exact supply, got %v", v.Supply)
+	}
+	if v.Max != nil {
+		t.Errorf("Expected null max as nil, got %v", v.Max)
+	}
+}
+
+// TestFits tests integer digits are checked against NUMERIC(precision, scale)
+func TestFits(t *testing.T) {
+	big, _ := New("589247000000000") // 15 integer digits
+	if err := big.Fits(20, 8); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange for NUMERIC(20, 8), got %v", err)
+	}
+	if err := big.Fits(38, 8); err != nil {
+		t.Errorf("Expected fit in NUMERIC(38, 8), got %v", err)
+	}
+	tiny, _ := New("0.000000000000000000001") // extra fraction digits are rounded
+	if err := tiny.Fits(20, 8); err != nil {
+		t.Errorf("Expected fraction digits to fit, got %v", err)
+	}
+}
+
+// TestScan tests NUMERIC values scanned from the driver
+func TestScan(t *testing.T) {
+	var d Decimal
+	if err := d.Scan([]byte("123.4500")); err != nil || d.String() != "123.45" {
+		t.Errorf("Expected 123.45, got %s (%v)", d, err)
+	}
+	if err := d.Scan(nil); err != nil || !d.IsZero() {
+		t.Errorf("Expected 0 for NULL, got %s (%v)", d, err)
+	}
+}
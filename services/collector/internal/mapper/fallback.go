@@ -0,0 +1,86 @@
+package mapper
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Embedded fallback ID map. Snapshot of CMC map entries (symbol -> CMC ID) ordered by CMC rank,
+// used when the id_map table and the CMC API can't answer a lookup (offline start, API down or key exhausted).
+// Regenerate from a live map, from services/collector, with: go run ./cmd -write-fallback-map 300
+// which overwrites internal/mapper/fallback_map.json (-fallback-map-out to write elsewhere).
+
+// FallbackMapPath is the embedded snapshot, relative to services/collector. Default output of WriteFallbackMap.
+const FallbackMapPath = "internal/mapper/fallback_map.json"
+
+//go:embed fallback_map.json
+var fallbackMapJSON []byte
+
+var (
+	fallbackOnce sync.Once
+	fallbackMap  []CmcCoinID
+	fallbackErr  error
+)
+
+// loadFallbackMap decodes the embedded snapshot once.
+func loadFallbackMap() ([]CmcCoinID, error) {
+	fallbackOnce.Do(func() {
+		if err := json.Unmarshal(fallbackMapJSON, &fallbackMap); err != nil {
+			fallbackErr = fmt.Errorf("failed to unmarshal embedded fallback map: %w", err)
+		}
+	})
+	return fallbackMap, fallbackErr
+}
+
+// FallbackCandidates returns the embedded snapshot entries for a symbol.
+func FallbackCandidates(symbol string) ([]CmcCoinID, error) {
+	entries, err := loadFallbackMap()
+	if err != nil {
+		return nil, err
+	}
+	var candidates []CmcCoinID
+	for _, c := range entries {
+		if strings.EqualFold(c.Symbol, symbol) {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates, nil
+}
+
+// FallbackTopCoins returns the first limit entries of the embedded snapshot (top coins by rank at snapshot time).
+func FallbackTopCoins(limit int) ([]CmcCoinID, error) {
+	entries, err := loadFallbackMap()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit value must be greater than 0, received %d", limit)
+	}
+	if limit > len(entries) {
+		limit = len(entries)
+	}
+	return entries[:limit], nil
+}
+
+// WriteFallbackMap writes map entries (ex. GetCMCTopCoins from a live map) as a fallback snapshot to path
+// (FallbackMapPath if empty). Ranks and is_active are kept so duplicate symbols resolve by rank.
+func WriteFallbackMap(coins []CmcCoinID, path string) error {
+	if len(coins) == 0 {
+		return fmt.Errorf("no map entries to write")
+	}
+	body, err := json.MarshalIndent(coins, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fallback map: %w", err)
+	}
+	if path == "" {
+		path = FallbackMapPath
+	}
+	if err := os.WriteFile(path, append(body, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write fallback map: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package mapper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFallbackMap tests the embedded snapshot decodes and resolves well known symbols
+func TestFallbackMap(t *testing.T) {
+	tests := []struct {
+		symbol string
+		id     int
+	}{
+		{"BTC", 1},
+		{"ETH", 1027},
+		{"sui", 20947},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			candidates, err := FallbackCandidates(tt.symbol)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			coin, err := resolveCandidates(tt.symbol, candidates, ResolveOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if coin.ID != tt.id {
+				t.Errorf("Expected %s ID to be %d, got %d", tt.symbol, tt.id, coin.ID)
+			}
+		})
+	}
+
+	top, err := FallbackTopCoins(1)
+	if err != nil || len(top) != 1 || top[0].ID != 1 {
+		t.Errorf("FallbackTopCoins(1) = %v, %v, want Bitcoin", top, err)
+	}
+}
+
+// TestWriteFallbackMap tests the snapshot is written to the given path with ranks kept
+func TestWriteFallbackMap(t *testing.T) {
+	rank := 1
+	path := filepath.Join(t.TempDir(), "fallback_map.json")
+	if err := WriteFallbackMap([]CmcCoinID{{ID: 1, Rank: &rank, Symbol: "BTC", IsActive: 1}}, path); err != nil {
+		t.Fatalf("WriteFallbackMap: %v", err)
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var coins []CmcCoinID
+	if err := json.Unmarshal(body, &coins); err != nil {
+		t.Fatal(err)
+	}
+	if len(coins) != 1 || coins[0].Rank == nil || *coins[0].Rank != 1 || coins[0].IsActive != 1 {
+		t.Errorf("Expected BTC with rank 1 and active, got %+v", coins)
+	}
+	if err := WriteFallbackMap(nil, path); err == nil {
+		t.Error("Expected error writing an empty map")
+	}
+}
@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// Lookup chain for symbol -> CMC ID: local id_map table (DB), then CMC API, then the embedded
+// fallback snapshot. An ambiguous symbol is a definitive answer and stops the chain, any other
+// error moves on to the next source.
+
+// LookupSource identifies which source answered a lookup.
+type LookupSource string
+
+const (
+	SourceDB       LookupSource = "db"
+	SourceAPI      LookupSource = "api"
+	SourceEmbedded LookupSource = "embedded"
+)
+
+// LookupSymbol resolves a symbol to a single CMC asset through the lookup chain (DB, API, embedded snapshot).
+func (i *IDMapService) LookupSymbol(ctx context.Context, symbol string, opts ResolveOptions) (*CmcCoinID, LookupSource, error) {
+	// 1. Local id_map table
+	if i.idMap != nil {
+		candidates, err := i.idMap.FindBySymbol(ctx, symbol)
+		if err != nil && !errors.Is(err, db.ErrNotConnected) {
+			i.logger.Warn("ID map lookup failed", "source", SourceDB, "symbol", symbol, "error", err)
+		} else if err == nil {
+			if coin, done, err := i.lookupResult(symbol, candidates, opts, SourceDB); done {
+				return coin, SourceDB, err
+			}
+		}
+	}
+
+	// 2. CMC API
+	candidates, err := i.GetCMCID(ctx, strings.ToUpper(symbol))
+	if err != nil {
+		i.logger.Warn("ID map lookup failed", "source", SourceAPI, "symbol", symbol, "error", err)
+	} else if coin, done, err := i.lookupResult(symbol, candidates, opts, SourceAPI); done {
+		return coin, SourceAPI, err
+	}
+
+	// 3. Embedded fallback snapshot
+	candidates, err = FallbackCandidates(symbol)
+	if err != nil {
+		return nil, SourceEmbedded, err
+	}
+	coin, _, err := i.lookupResult(symbol, candidates, opts, SourceEmbedded)
+	return coin, SourceEmbedded, err
+}
+
+// lookupResult resolves the candidates from a source. done is false if the chain should try the next source.
+func (i *IDMapService) lookupResult(symbol string, candidates []CmcCoinID, opts ResolveOptions, source LookupSource) (*CmcCoinID, bool, error) {
+	coin, err := resolveCandidates(symbol, candidates, opts)
+	switch {
+	case err == nil:
+		i.logger.Info("ID map lookup", "source", source, "symbol", symbol, "cmc_id", coin.ID, "slug", coin.Slug)
+		return coin, true, nil
+	case errors.Is(err, ErrAmbiguousSymbol):
+		i.logger.Warn("ID map lookup ambiguous", "source", source, "symbol", symbol, "candidates", len(candidates))
+		return nil, true, err
+	default:
+		i.logger.Info("ID map lookup missed", "source", source, "symbol", symbol)
+		return nil, false, fmt.Errorf("%s lookup: %w", source, err)
+	}
+}
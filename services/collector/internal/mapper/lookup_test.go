@@ -0,0 +1,40 @@
+package mapper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+)
+
+// TestLookupSymbolDB tests the local id_map answers first and delisted entries are not candidates
+func TestLookupSymbolDB(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryIDMapRepo()
+	synced := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	if _, err := repo.UpsertPage(ctx, suiCandidates, "active", synced); err != nil {
+		t.Fatal(err)
+	}
+	// Next sync only sees the real asset and the copycat
+	next := synced.Add(24 * time.Hour)
+	if _, err := repo.UpsertPage(ctx, suiCandidates[:2], "active", next); err != nil {
+		t.Fatal(err)
+	}
+	if delisted, err := repo.MarkDelisted(ctx, next); err != nil || delisted != 1 {
+		t.Fatalf("Expected 1 entry delisted, got %d (%v)", delisted, err)
+	}
+
+	service := NewIDMapService(&config.AppConfig{}, nil, nil, repo)
+	coin, source, err := service.LookupSymbol(ctx, "sui", ResolveOptions{Strict: true, Slug: "sui"})
+	if err != nil {
+		t.Fatalf("LookupSymbol: %v", err)
+	}
+	if source != SourceDB || coin.ID != 20947 {
+		t.Errorf("Expected CMC ID 20947 from %s, got %d from %s", SourceDB, coin.ID, source)
+	}
+	candidates, _ := repo.FindBySymbol(ctx, "SUI")
+	if len(candidates) != 2 {
+		t.Errorf("Expected 2 listed candidates, got %d", len(candidates))
+	}
+}
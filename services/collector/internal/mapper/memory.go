@@ -0,0 +1,77 @@
+package mapper
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// MemoryIDMapRepo is an in-memory IDMapRepo for tests. WithTx doesn't roll back (db.NopTx).
+type MemoryIDMapRepo struct {
+	db.NopTx
+	mu      sync.Mutex
+	entries map[int]memoryIDMapEntry // by CMC ID
+}
+
+// memoryIDMapEntry is a stored map entry with its sync state.
+type memoryIDMapEntry struct {
+	coin          CmcCoinID
+	listingStatus string
+	lastSyncedAt  time.Time
+	delisted      bool
+}
+
+// NewMemoryIDMapRepo creates a MemoryIDMapRepo holding coins as listed entries.
+func NewMemoryIDMapRepo(coins ...CmcCoinID) *MemoryIDMapRepo {
+	r := &MemoryIDMapRepo{entries: make(map[int]memoryIDMapEntry)}
+	for _, c := range coins {
+		r.entries[c.ID] = memoryIDMapEntry{coin: c, listingStatus: "active"}
+	}
+	return r
+}
+
+// UpsertPage stores map entries seen by the sync started at syncedAt and clears their delisted flag.
+func (r *MemoryIDMapRepo) UpsertPage(ctx context.Context, coins []CmcCoinID, listingStatus string, syncedAt time.Time) ([]db.RowError, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, coin := range coins {
+		r.entries[coin.ID] = memoryIDMapEntry{coin: coin, listingStatus: listingStatus, lastSyncedAt: syncedAt}
+	}
+	return nil, nil
+}
+
+// MarkDelisted marks the entries not seen by the sync started at syncedAt as delisted.
+func (r *MemoryIDMapRepo) MarkDelisted(ctx context.Context, syncedAt time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delisted := 0
+	for id, e := range r.entries {
+		if e.delisted || !e.lastSyncedAt.Before(syncedAt) {
+			continue
+		}
+		e.delisted = true
+		r.entries[id] = e
+		delisted++
+	}
+	return delisted, nil
+}
+
+// FindBySymbol returns the listed entries for a symbol (case insensitive), ordered by CMC ID.
+func (r *MemoryIDMapRepo) FindBySymbol(ctx context.Context, symbol string) ([]CmcCoinID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var coins []CmcCoinID
+	for _, e := range r.entries {
+		if !e.delisted && strings.EqualFold(e.coin.Symbol, symbol) {
+			coins = append(coins, e.coin)
+		}
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i].ID < coins[j].ID })
+	return coins, nil
+}
@@ -0,0 +1,124 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Symbol resolution policy. CMC's /map?symbol= often returns several assets for one ticker symbol
+// (scam tokens reusing "SUI" or "ICP", old delisted assets, wrapped tokens on other platforms).
+// Candidates are filtered by slug and platform, active assets are preferred over inactive ones and
+// the best ranked asset wins. If the policy can't pick a single asset an AmbiguousSymbolError is
+// returned with the candidates so the caller (ex. website) can ask the user to choose by slug.
+
+var (
+	// ErrSymbolNotFound is returned when no CMC asset matches a symbol and the resolve options.
+	ErrSymbolNotFound = errors.New("symbol not found")
+	// ErrAmbiguousSymbol is matched by AmbiguousSymbolError with errors.Is.
+	ErrAmbiguousSymbol = errors.New("ambiguous symbol")
+)
+
+// AmbiguousSymbolError is returned when more than one CMC asset matches a symbol.
+type AmbiguousSymbolError struct {
+	Symbol     string
+	Candidates []CmcCoinID
+}
+
+// Error lists the candidates as "id/slug (rank N)".
+func (e *AmbiguousSymbolError) Error() string {
+	parts := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		rank := "unranked"
+		if c.Rank != nil && *c.Rank > 0 {
+			rank = fmt.Sprintf("rank %d", *c.Rank)
+		}
+		parts[i] = fmt.Sprintf("%d/%s (%s)", c.ID, c.Slug, rank)
+	}
+	return fmt.Sprintf("symbol %s is ambiguous, %d candidates: %s",
+		e.Symbol, len(e.Candidates), strings.Join(parts, ", "))
+}
+
+// Unwrap allows errors.Is(err, ErrAmbiguousSymbol).
+func (e *AmbiguousSymbolError) Unwrap() error {
+	return ErrAmbiguousSymbol
+}
+
+// ResolveSymbol looks up a symbol on CMC and resolves it to a single asset using the resolution policy.
+func (i *IDMapService) ResolveSymbol(ctx context.Context, symbol string, opts ResolveOptions) (*CmcCoinID, error) {
+	candidates, err := i.GetCMCID(ctx, strings.ToUpper(symbol))
+	if err != nil {
+		return nil, err
+	}
+	coin, err := resolveCandidates(symbol, candidates, opts)
+	if err != nil {
+		i.logger.Warn("Could not resolve symbol", "symbol", symbol, "candidates", len(candidates), "error", err)
+		return nil, err
+	}
+	i.logger.Info("Resolved symbol", "symbol", symbol, "cmc_id", coin.ID, "slug", coin.Slug, "candidates", len(candidates))
+	return coin, nil
+}
+
+// resolveCandidates applies the resolution policy to the assets returned by CMC for a symbol.
+func resolveCandidates(symbol string, candidates []CmcCoinID, opts ResolveOptions) (*CmcCoinID, error) {
+	// Filter by slug and platform
+	var filtered []CmcCoinID
+	for _, c := range candidates {
+		if opts.Slug != "" && !strings.EqualFold(c.Slug, opts.Slug) {
+			continue
+		}
+		if opts.Platform != "" && !matchesPlatform(c.Platform, opts.Platform) {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("%s: %w", symbol, ErrSymbolNotFound)
+	}
+	if len(filtered) == 1 {
+		return &filtered[0], nil
+	}
+
+	// Prefer active assets if there are any
+	var active []CmcCoinID
+	for _, c := range filtered {
+		if c.IsActive == 1 {
+			active = append(active, c)
+		}
+	}
+	if len(active) == 1 {
+		return &active[0], nil
+	}
+	if len(active) > 1 {
+		filtered = active
+	}
+	if opts.Strict {
+		return nil, &AmbiguousSymbolError{Symbol: symbol, Candidates: filtered}
+	}
+
+	// Prefer the best (lowest) rank. Unranked assets can't win and a tie is ambiguous.
+	var ranked []CmcCoinID
+	for _, c := range filtered {
+		if c.Rank != nil && *c.Rank > 0 {
+			ranked = append(ranked, c)
+		}
+	}
+	sort.Slice(ranked, func(a, b int) bool { return *ranked[a].Rank < *ranked[b].Rank })
+	if len(ranked) == 0 || (len(ranked) > 1 && *ranked[0].Rank == *ranked[1].Rank) {
+		return nil, &AmbiguousSymbolError{Symbol: symbol, Candidates: filtered}
+	}
+	return &ranked[0], nil
+}
+
+// matchesPlatform reports whether a token's platform matches the platform option (slug, symbol or name).
+// Native coins have no platform and only match the option "native".
+func matchesPlatform(p *Platform, platform string) bool {
+	if p == nil {
+		return strings.EqualFold(platform, "native")
+	}
+	return strings.EqualFold(p.Slug, platform) ||
+		strings.EqualFold(p.Symbol, platform) ||
+		strings.EqualFold(p.Name, platform)
+}
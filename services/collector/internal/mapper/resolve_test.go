@@ -0,0 +1,119 @@
+package mapper
+
+import (
+	"errors"
+	"testing"
+)
+
+func rank(r int) *int { return &r }
+
+// suiCandidates mimics CMC /map?symbol=SUI: the real asset, a ranked copycat and an unranked inactive scam.
+var suiCandidates = []CmcCoinID{
+	{ID: 20947, Rank: rank(20), Symbol: "SUI", Name: "Sui", Slug: "sui", IsActive: 1},
+	{ID: 29000, Rank: rank(3500), Symbol: "SUI", Name: "Sui Inu", Slug: "sui-inu", IsActive: 1,
+		Platform: &Platform{Name: "Ethereum", Symbol: "ETH", Slug: "ethereum"}},
+	{ID: 31000, Symbol: "SUI", Name: "SUI Token", Slug: "sui-token", IsActive: 0},
+}
+
+// TestResolveCandidates tests the symbol resolution policy
+func TestResolveCandidates(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []CmcCoinID
+		opts       ResolveOptions
+		wantID     int
+		wantErr    error
+	}{
+		{
+			name:       "best rank wins",
+			candidates: suiCandidates,
+			wantID:     20947,
+		},
+		{
+			name:       "slug filter",
+			candidates: suiCandidates,
+			opts:       ResolveOptions{Slug: "sui-inu"},
+			wantID:     29000,
+		},
+		{
+			name:       "platform filter",
+			candidates: suiCandidates,
+			opts:       ResolveOptions{Platform: "ethereum"},
+			wantID:     29000,
+		},
+		{
+			name:       "native platform filter",
+			candidates: suiCandidates,
+			opts:       ResolveOptions{Platform: "native"},
+			wantID:     20947, // inactive unranked native scam is dropped for the active one
+		},
+		{
+			name:       "strict with several active candidates",
+			candidates: suiCandidates,
+			opts:       ResolveOptions{Strict: true},
+			wantErr:    ErrAmbiguousSymbol,
+		},
+		{
+			name: "active preferred over inactive",
+			candidates: []CmcCoinID{
+				{ID: 1, Rank: rank(5), Slug: "old", IsActive: 0},
+				{ID: 2, Slug: "new", IsActive: 1},
+			},
+			opts:   ResolveOptions{Strict: true},
+			wantID: 2,
+		},
+		{
+			name: "no ranked candidates",
+			candidates: []CmcCoinID{
+				{ID: 1, Slug: "a", IsActive: 1},
+				{ID: 2, Slug: "b", IsActive: 1},
+			},
+			wantErr: ErrAmbiguousSymbol,
+		},
+		{
+			name: "rank tie",
+			candidates: []CmcCoinID{
+				{ID: 1, Rank: rank(7), Slug: "a", IsActive: 1},
+				{ID: 2, Rank: rank(7), Slug: "b", IsActive: 1},
+			},
+			wantErr: ErrAmbiguousSymbol,
+		},
+		{
+			name:       "no match",
+			candidates: suiCandidates,
+			opts:       ResolveOptions{Slug: "bitcoin"},
+			wantErr:    ErrSymbolNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coin, err := resolveCandidates("SUI", tt.candidates, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if coin.ID != tt.wantID {
+				t.Errorf("resolved ID = %d, want %d", coin.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+// TestAmbiguousSymbolErrorCandidates tests the candidates are returned with the error
+func TestAmbiguousSymbolErrorCandidates(t *testing.T) {
+	_, err := resolveCandidates("SUI", suiCandidates, ResolveOptions{Strict: true})
+
+	var ambiguous *AmbiguousSymbolError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("error = %v, want *AmbiguousSymbolError", err)
+	}
+	if len(ambiguous.Candidates) != 2 {
+		t.Errorf("candidates = %d, want 2 active candidates", len(ambiguous.Candidates))
+	}
+}
@@ -2,36 +2,42 @@ package mapper
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
 	"strconv"
 
 	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/cmc"
 )
 
 // Mapper service provides utilities to get CMC ID's for coins and unmarshal the response for use in other services.
-// Mapper only gets data from Coinmarketcap API. DB Updates are handled by internal/coins and internal/ticker services.
+// Mapper gets data from Coinmarketcap API and keeps a local copy of the full ID map in the id_map table (SyncIDMap).
+// Other DB updates are handled by internal/coins and internal/ticker services.
 
 // IDMapInterface defines the contract for CMC ID mapping operations
 type IDMapInterface interface {
-	GetCMCID(ctx context.Context, symbol string) ([]byte, error)
-	GetCMCTopCoins(ctx context.Context, limit int) ([]byte, error)
-	UnmarshalCMCID(body []byte, client *http.Client)
+	GetCMCID(ctx context.Context, symbol string) ([]CmcCoinID, error)
+	GetCMCTopCoins(ctx context.Context, limit int) ([]CmcCoinID, error)
+	UnmarshalCMCID(body []byte) (*CmcIdMapResponse, error)
+	ValidateCMCID(ctx context.Context, cmcID int, symbol string) (*CmcCoinID, error)
+	ResolveSymbol(ctx context.Context, symbol string, opts ResolveOptions) (*CmcCoinID, error)
+	SyncIDMap(ctx context.Context) (*SyncResult, error)
+	LookupSymbol(ctx context.Context, symbol string, opts ResolveOptions) (*CmcCoinID, LookupSource, error)
 }
 
 // IDMapService implements the IDMapInterface
 type IDMapService struct {
-	apiKey string
-	mapURL string
-	client *http.Client
-	logger *slog.Logger
+	mapURL   string
+	pageSize int // entries per page for SyncIDMap
+	client   *cmc.Client
+	idMap    IDMapRepo // local copy of the ID map (id_map table), nil = API and embedded snapshot only
+	logger   *slog.Logger
 }
 
-// NewIDMapService creates a new instance of IDMapService struct
-func NewIDMapService(app *config.AppConfig, logger *slog.Logger, client *http.Client) *IDMapService {
+// NewIDMapService creates a new instance of IDMapService struct. idMap stores the local copy of the ID map.
+func NewIDMapService(app *config.AppConfig, logger *slog.Logger, client *cmc.Client, idMap IDMapRepo) *IDMapService {
 	// Validate required dependencies (panic if missing)
 	if app == nil {
 		panic("App configuration required to create IDMapService")
@@ -40,98 +46,132 @@ func NewIDMapService(app *config.AppConfig, logger *slog.Logger, client *http.Cl
 	if logger == nil {
 		logger = slog.Default()
 	}
-	if app.CMC.APIKey == "" {
-		logger.Warn("No API key provided - requires API key")
-	}
 	if app.CMC.IDMapURL == "" {
 		logger.Warn("No ID map URL provided - requires ID map URL")
 	}
 	if client == nil {
-		logger.Warn("No HTTP client provided - requires HTTP client")
+		logger.Warn("No CMC client provided - requires CMC client")
+	}
+	if idMap == nil {
+		logger.Warn("No ID map repository provided - lookups use the API and embedded snapshot only")
+	}
+	pageSize := app.CMC.IDMapPageSize
+	if pageSize <= 0 || pageSize > 5000 {
+		logger.Warn("Invalid ID map page size - using CMC maximum", "page_size", pageSize)
+		pageSize = 5000
 	}
 
 	logger.Info("IDMapService initialized successfully")
 
 	// Return struct with values
 	return &IDMapService{
-		apiKey: app.CMC.APIKey,
-		mapURL: app.CMC.IDMapURL,
-		client: client,
-		logger: logger,
+		mapURL:   app.CMC.IDMapURL,
+		pageSize: pageSize,
+		client:   client,
+		idMap:    idMap,
+		logger:   logger,
 	}
 
 }
 
-// LookupCMCID builds the request to look up the corresponding Coinmarketcap ID for a given symbol (ex. ETH -> 1027)
-func (i *IDMapService) GetCMCID(ctx context.Context, symbol string) ([]byte, error) {
+// GetCMCID looks up the corresponding Coinmarketcap ID(s) for a given symbol (ex. ETH -> 1027).
+// CMC can return more than one asset for the same symbol.
+func (i *IDMapService) GetCMCID(ctx context.Context, symbol string) ([]CmcCoinID, error) {
 	i.logger.Info("Looking up Coinmarketcap ID for:", "symbol", symbol)
 
-	// Build request
-	req, err := http.NewRequest("GET", i.mapURL, nil)
-	if err != nil {
-		return nil, err
+	// Validate symbol parameter
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol required")
 	}
 
-	// Set headers & Build query parameters
+	// Build query parameters
 	q := url.Values{}
 	q.Add("symbol", symbol)
-	req.URL.RawQuery = q.Encode()
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-CMC_PRO_API_KEY", i.apiKey)
-
-	// Execute the request
-	resp, err := i.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
 
-	// Handle the response body
-	body, err := io.ReadAll(resp.Body)
+	idMap, err := i.fetchIDMap(ctx, q)
 	if err != nil {
 		return nil, err
 	}
-	// Just for testing purposes
-	fmt.Println(string(body))
-
-	return body, nil
+	return idMap.Data, nil
 }
 
 // GetCMCTopCoins gets a set of top coins based on limit parameter (top 10, top 50, etc.)
-func (i *IDMapService) GetCMCTopCoins(ctx context.Context, limit int) ([]byte, error) {
+func (i *IDMapService) GetCMCTopCoins(ctx context.Context, limit int) ([]CmcCoinID, error) {
 	i.logger.Info("getting top coins for:", "limit", limit)
 
 	// Validate limit parameter to be greater than 0
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit value must be greater than 0, received %d", limit)
 	}
-	// Build request
-	req, err := http.NewRequestWithContext(ctx, "GET", i.mapURL, nil)
-	if err != nil {
-		return nil, err
-	}
 
-	// Set headers & Build query parameters
+	// Build query parameters
 	q := url.Values{}
 	q.Add("limit", strconv.Itoa(limit))
 	q.Add("sort", "cmc_rank")
-	req.URL.RawQuery = q.Encode()
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-CMC_PRO_API_KEY", i.apiKey)
 
-	// Execute the request
-	resp, err := i.client.Do(req)
+	idMap, err := i.fetchIDMap(ctx, q)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	return idMap.Data, nil
+}
+
+// UnmarshalCMCID unmarshals the response body into CmcIdMapResponse struct (symbol -> CMCID)
+// and returns an error if the CMC API reported one in the response status.
+func (i *IDMapService) UnmarshalCMCID(body []byte) (*CmcIdMapResponse, error) {
+	var idMap CmcIdMapResponse
+	if err := json.Unmarshal(body, &idMap); err != nil {
+		i.logger.Error("failed to unmarshal response", "error", err)
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// Check for API errors
+	if idMap.Status.ErrorCode != 0 {
+		errorMsg := "API error"
+		if idMap.Status.ErrorMessage != nil {
+			errorMsg = *idMap.Status.ErrorMessage
+		}
+		i.logger.Error("Coinmarketcap API returned error",
+			"error_code", idMap.Status.ErrorCode,
+			"error_message", errorMsg,
+			"credit_count", idMap.Status.CreditCount)
+		return nil, fmt.Errorf("API error (code %d): %s", idMap.Status.ErrorCode, errorMsg)
+	}
+	return &idMap, nil
+}
+
+// ValidateCMCID checks that a CMC ID exists on Coinmarketcap for the given symbol and returns its map entry.
+// Used before a coin is added to tracked_coins so only real CMC assets are tracked.
+func (i *IDMapService) ValidateCMCID(ctx context.Context, cmcID int, symbol string) (*CmcCoinID, error) {
+	if cmcID <= 0 {
+		return nil, fmt.Errorf("CMC ID must be greater than 0, received %d", cmcID)
+	}
+	candidates, err := i.GetCMCID(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
-
-	return body, nil
+	for _, coin := range candidates {
+		if coin.ID == cmcID {
+			return &coin, nil
+		}
+	}
+	return nil, fmt.Errorf("CMC ID %d not found for symbol %s", cmcID, symbol)
 }
 
-// UnmarshallCMCID unmarshalls the response body into CmcIdResponse struct (symbol -> CMCID)
-func (i *IDMapService) UnmarshalCMCID(body []byte, client *http.Client) {}
+// fetchIDMap calls the CMC /map endpoint with the query parameters and decodes the response.
+func (i *IDMapService) fetchIDMap(ctx context.Context, q url.Values) (*CmcIdMapResponse, error) {
+	// Execute request (retries, status checks and credit accounting in internal/cmc)
+	body, err := i.client.GetIfBudget(ctx, "map", i.mapURL, q)
+	if err != nil {
+		return nil, err
+	}
+
+	idMap, err := i.UnmarshalCMCID(body)
+	if err != nil {
+		return nil, err
+	}
+	i.logger.Info("Successfully fetched and decoded CMC ID map",
+		"coins_count", len(idMap.Data),
+		"credit_count", idMap.Status.CreditCount)
+	return idMap, nil
+}
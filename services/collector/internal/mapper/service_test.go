@@ -0,0 +1,87 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/cmc"
+	"github.com/jdbdev/go-cmc/internal/credits"
+)
+
+// TestFetchIDMapBudgetSpent tests the ID map isn't called once the daily credit budget is spent
+func TestFetchIDMapBudgetSpent(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 1}, "data": [{"id": 1, "symbol": "BTC"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.AppConfig{CMC: config.CMCSettings{APIKey: "test-key", IDMapURL: server.URL, DailyCreditBudget: 2}}
+	accountant := credits.NewAccountant(cfg, nil, nil)
+	service := NewIDMapService(cfg, nil, cmc.NewClient(cfg, server.Client(), accountant, nil), nil)
+
+	if _, err := service.GetCMCTopCoins(context.Background(), 10); err != nil {
+		t.Fatalf("Expected a call within budget, got %v", err)
+	}
+	accountant.Record(context.Background(), "quotes", 1) // budget spent
+
+	if _, err := service.GetCMCTopCoins(context.Background(), 10); !errors.Is(err, credits.ErrBudgetSpent) {
+		t.Errorf("Expected credits.ErrBudgetSpent, got %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 ID map request, got %d", got)
+	}
+}
+
+// TestSyncIDMap tests pages are written as they are fetched and entries are only delisted after a complete sync
+func TestSyncIDMap(t *testing.T) {
+	failInactive := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch status := r.URL.Query().Get("listing_status"); {
+		case status == "inactive" && failInactive:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": {"error_code": 400, "error_message": "bad request"}}`))
+		case status == "active" && r.URL.Query().Get("start") == "1":
+			w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 1},
+				"data": [{"id": 1, "symbol": "BTC"}, {"id": 1027, "symbol": "ETH"}]}`))
+		case status == "active":
+			w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 1}, "data": [{"id": 20947, "symbol": "SUI"}]}`))
+		default:
+			w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 1}, "data": []}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	repo := NewMemoryIDMapRepo(CmcCoinID{ID: 99999, Symbol: "OLD"})
+	cfg := &config.AppConfig{CMC: config.CMCSettings{APIKey: "test-key", IDMapURL: server.URL, IDMapPageSize: 2}}
+	service := NewIDMapService(cfg, nil, cmc.NewClient(cfg, server.Client(), nil, nil), repo)
+
+	if _, err := service.SyncIDMap(ctx); err == nil {
+		t.Fatal("Expected the failed inactive page to fail the sync")
+	}
+	if coins, _ := repo.FindBySymbol(ctx, "SUI"); len(coins) != 1 {
+		t.Errorf("Expected the active pages to be kept, got %v", coins)
+	}
+	if coins, _ := repo.FindBySymbol(ctx, "OLD"); len(coins) != 1 {
+		t.Error("Expected no entry delisted by a partial sync")
+	}
+
+	failInactive = false
+	result, err := service.SyncIDMap(ctx)
+	if err != nil {
+		t.Fatalf("SyncIDMap: %v", err)
+	}
+	if result.Upserted != 3 || result.Delisted != 1 || result.ByStatus["active"] != 3 {
+		t.Errorf("Expected 3 entries upserted and 1 delisted, got %+v", result)
+	}
+	if coins, _ := repo.FindBySymbol(ctx, "OLD"); len(coins) != 0 {
+		t.Error("Expected the entry missing from a complete sync to be delisted")
+	}
+}
@@ -0,0 +1,179 @@
+package mapper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// SQL for the id_map table (db/migrations/005), written by SyncIDMap and read by LookupSymbol.
+// Every entry seen in a sync gets last_synced_at set to the sync start time and delisted_at cleared.
+// Pages are written with the bulk path (db/bulk.go), many rows per statement.
+var idMapUpsert = db.BulkUpsert{
+	Table: "id_map",
+	Columns: []string{"cmc_id", "rank", "name", "symbol", "slug", "is_active", "listing_status",
+		"first_historical_data", "last_historical_data",
+		"platform_id", "platform_name", "platform_symbol", "platform_slug", "platform_token_address",
+		"last_synced_at"},
+	OnConflict: `
+		ON CONFLICT (cmc_id) DO UPDATE SET
+			rank = EXCLUDED.rank,
+			name = EXCLUDED.name,
+			symbol = EXCLUDED.symbol,
+			slug = EXCLUDED.slug,
+			is_active = EXCLUDED.is_active,
+			listing_status = EXCLUDED.listing_status,
+			first_historical_data = EXCLUDED.first_historical_data,
+			last_historical_data = EXCLUDED.last_historical_data,
+			platform_id = EXCLUDED.platform_id,
+			platform_name = EXCLUDED.platform_name,
+			platform_symbol = EXCLUDED.platform_symbol,
+			platform_slug = EXCLUDED.platform_slug,
+			platform_token_address = EXCLUDED.platform_token_address,
+			delisted_at = NULL,
+			last_synced_at = EXCLUDED.last_synced_at,
+			updated_at = CURRENT_TIMESTAMP`,
+}
+
+const (
+	selectIDMapBySymbolSQL = `
+		SELECT cmc_id, COALESCE(rank, 0), name, symbol, slug, is_active,
+			platform_id, platform_name, platform_symbol, platform_slug, platform_token_address
+		FROM id_map
+		WHERE UPPER(symbol) = UPPER($1) AND delisted_at IS NULL`
+
+	markDelistedSQL = `
+		UPDATE id_map SET delisted_at = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE last_synced_at < $1 AND delisted_at IS NULL`
+)
+
+// IDMapRepo stores the local copy of the CMC ID map, injected by NewIDMapService. SyncIDMap writes every page
+// in its own short transaction.
+type IDMapRepo interface {
+	db.TxRunner
+	// UpsertPage inserts or updates the entries of a CMC map page seen by the sync started at syncedAt.
+	// Entries rejected because of their data are returned, the others are written.
+	UpsertPage(ctx context.Context, coins []CmcCoinID, listingStatus string, syncedAt time.Time) ([]db.RowError, error)
+	// MarkDelisted marks the entries not seen by the sync started at syncedAt as delisted.
+	MarkDelisted(ctx context.Context, syncedAt time.Time) (int, error)
+	// FindBySymbol returns the listed entries for a symbol (case insensitive), delisted entries are skipped.
+	FindBySymbol(ctx context.Context, symbol string) ([]CmcCoinID, error)
+}
+
+// PostgresIDMapRepo is the id_map table backed IDMapRepo
+type PostgresIDMapRepo struct {
+	database *db.Database
+}
+
+// NewPostgresIDMapRepo creates an IDMapRepo on database (nil returns db.ErrNotConnected on every call).
+func NewPostgresIDMapRepo(database *db.Database) *PostgresIDMapRepo {
+	return &PostgresIDMapRepo{database: database}
+}
+
+// WithTx runs fn in a transaction shared by the repositories on the same database.
+func (r *PostgresIDMapRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.database.WithTx(ctx, fn)
+}
+
+// UpsertPage upserts the id_map rows of a page of CMC map entries in one transaction.
+func (r *PostgresIDMapRepo) UpsertPage(ctx context.Context, coins []CmcCoinID, listingStatus string, syncedAt time.Time) ([]db.RowError, error) {
+	rows := make([][]any, len(coins))
+	for i, coin := range coins {
+		var (
+			rank                                       *int
+			platformID                                 *int
+			platformName, platformSymbol, platformSlug *string
+			platformTokenAddress                       *string
+		)
+		if coin.Rank != nil && *coin.Rank > 0 {
+			rank = coin.Rank
+		}
+		if p := coin.Platform; p != nil {
+			platformID = &p.ID
+			platformName, platformSymbol, platformSlug = &p.Name, &p.Symbol, &p.Slug
+			platformTokenAddress = &p.TokenAddress
+		}
+		rows[i] = []any{coin.ID, rank, coin.Name, coin.Symbol, coin.Slug, coin.IsActive == 1, listingStatus,
+			coin.FirstHistoricalData, coin.LastHistoricalData,
+			platformID, platformName, platformSymbol, platformSlug, platformTokenAddress,
+			syncedAt}
+	}
+
+	var rejects []db.RowError
+	err := r.WithTx(ctx, func(ctx context.Context) error {
+		tx, _ := db.TxFromContext(ctx)
+		result, err := idMapUpsert.Exec(ctx, tx, rows, nil)
+		rejects = result.Rejected
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upsert id_map: %w", err)
+	}
+	return rejects, nil
+}
+
+// MarkDelisted sets delisted_at on id_map rows that were not seen by the sync started at syncedAt.
+func (r *PostgresIDMapRepo) MarkDelisted(ctx context.Context, syncedAt time.Time) (int, error) {
+	q, err := r.database.Querier(ctx)
+	if err != nil {
+		return 0, err
+	}
+	res, err := q.ExecContext(ctx, markDelistedSQL, syncedAt)
+	if err != nil {
+		return 0, fmt.Errorf("mark delisted id_map rows: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// FindBySymbol reads the listed id_map entries for a symbol (delisted entries are skipped).
+func (r *PostgresIDMapRepo) FindBySymbol(ctx context.Context, symbol string) ([]CmcCoinID, error) {
+	conn, err := r.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, selectIDMapBySymbolSQL, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("select id_map (symbol %s): %w", symbol, err)
+	}
+	defer rows.Close()
+
+	var coins []CmcCoinID
+	for rows.Next() {
+		var (
+			c        CmcCoinID
+			rank     int
+			isActive bool
+			pID      sql.NullInt64
+			pName    sql.NullString
+			pSymbol  sql.NullString
+			pSlug    sql.NullString
+			pAddress sql.NullString
+		)
+		if err := rows.Scan(&c.ID, &rank, &c.Name, &c.Symbol, &c.Slug, &isActive,
+			&pID, &pName, &pSymbol, &pSlug, &pAddress); err != nil {
+			return nil, fmt.Errorf("scan id_map row: %w", err)
+		}
+		if rank > 0 {
+			c.Rank = &rank
+		}
+		if isActive {
+			c.IsActive = 1
+		}
+		if pID.Valid {
+			c.Platform = &Platform{ID: int(pID.Int64), Name: pName.String, Symbol: pSymbol.String,
+				Slug: pSlug.String, TokenAddress: pAddress.String}
+		}
+		coins = append(coins, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate id_map rows: %w", err)
+	}
+	return coins, nil
+}
@@ -0,0 +1,82 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// listingStatuses are the CMC listing_status values synced into id_map. Inactive and untracked
+// assets are kept so delisted coins can still be identified by CMC ID.
+var listingStatuses = []string{"active", "inactive", "untracked"}
+
+// SyncIDMap pages through the CMC /map endpoint for every listing status and upserts the entries into the
+// id_map table, each page in its own short transaction (no transaction is held during CMC calls). Rows missing
+// from a complete sync are marked as delisted. A failed page stops the sync before anything is delisted, the
+// pages already written are kept.
+func (i *IDMapService) SyncIDMap(ctx context.Context) (*SyncResult, error) {
+	if i.idMap == nil {
+		return nil, db.ErrNotConnected
+	}
+	i.logger.Info("Starting ID map sync", "page_size", i.pageSize)
+
+	syncedAt := time.Now().UTC()
+	result := &SyncResult{ByStatus: make(map[string]int)}
+
+	for _, status := range listingStatuses {
+		// CMC start parameter is 1-based
+		for start := 1; ; start += i.pageSize {
+			q := url.Values{}
+			q.Add("listing_status", status)
+			q.Add("start", strconv.Itoa(start))
+			q.Add("limit", strconv.Itoa(i.pageSize))
+			q.Add("sort", "id")
+
+			page, err := i.fetchIDMap(ctx, q)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch %s ID map page (start %d): %w", status, start, err)
+			}
+			result.Credits += page.Status.CreditCount
+
+			rejects, err := i.idMap.UpsertPage(ctx, page.Data, status, syncedAt)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range rejects {
+				i.logger.Warn("ID map entry rejected", "cmc_id", page.Data[r.Index].ID, "listing_status", status, "error", r.Err)
+			}
+			result.Rejected += len(rejects)
+			result.Upserted += len(page.Data) - len(rejects)
+			result.ByStatus[status] += len(page.Data)
+
+			// Last page is shorter than the page size
+			if len(page.Data) < i.pageSize {
+				break
+			}
+		}
+	}
+
+	// Rejected entries weren't marked as seen, delisting would wrongly delist them
+	if result.Rejected > 0 {
+		i.logger.Warn("ID map sync incomplete - not marking delisted entries", "rejected", result.Rejected)
+	} else {
+		var err error
+		if result.Delisted, err = i.idMap.MarkDelisted(ctx, syncedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	i.logger.Info("ID map sync complete",
+		"upserted", result.Upserted,
+		"rejected", result.Rejected,
+		"delisted", result.Delisted,
+		"active", result.ByStatus["active"],
+		"inactive", result.ByStatus["inactive"],
+		"untracked", result.ByStatus["untracked"],
+		"credit_count", result.Credits)
+	return result, nil
+}
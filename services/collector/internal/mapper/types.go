@@ -1,15 +1,52 @@
 package mapper
 
+import "github.com/jdbdev/go-cmc/internal/cmc"
+
 // CmcIdMapResponse is the struct to store the ID map from Coinmarketcap.
 // The CMC endpoint /map returns multiple tokens under the key "data"
 type CmcIdMapResponse struct {
-	Data []CmcCoinID `json:"data"`
+	Status Status      `json:"status"`
+	Data   []CmcCoinID `json:"data"`
 }
 
+// Status holds the response status from CMC API (shared with the ticker in internal/cmc).
+type Status = cmc.Status
+
 // CmcCoinID stores only the required fields for the app
 type CmcCoinID struct {
-	ID     int    `json:"id"`
-	Symbol string `json:"symbol"`
-	Name   string `json:"name"`
-	Slug   string `json:"slug"`
+	ID       int       `json:"id"`
+	Rank     *int      `json:"rank"` // CMC rank, null or 0 for unranked assets
+	Symbol   string    `json:"symbol"`
+	Name     string    `json:"name"`
+	Slug     string    `json:"slug"`
+	IsActive int       `json:"is_active"` // 1 if active on CMC, 0 if inactive
+	Platform *Platform `json:"platform"`  // null for native coins (BTC, ETH, ...)
+
+	FirstHistoricalData *string `json:"first_historical_data"`
+	LastHistoricalData  *string `json:"last_historical_data"`
+}
+
+// Platform holds the platform a token is issued on (ex. Ethereum for ERC-20 tokens).
+type Platform struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Symbol       string `json:"symbol"`
+	Slug         string `json:"slug"`
+	TokenAddress string `json:"token_address"`
+}
+
+// ResolveOptions holds the optional filters and policy used by ResolveSymbol.
+type ResolveOptions struct {
+	Slug     string // only consider the asset with this CMC slug (ex. "sui")
+	Platform string // only consider tokens on this platform, matched on platform slug, symbol or name
+	Strict   bool   // return AmbiguousSymbolError whenever more than one candidate is left after filters
+}
+
+// SyncResult holds the outcome of a full ID map sync.
+type SyncResult struct {
+	Upserted int            // map entries written to id_map
+	Rejected int            // map entries rejected by the database (no entries are delisted then)
+	Delisted int            // id_map rows missing from CMC, marked with delisted_at
+	ByStatus map[string]int // entries fetched per listing_status
+	Credits  int            // API credits used by all pages
 }
@@ -0,0 +1,83 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jdbdev/go-cmc/internal/ticker"
+)
+
+// ReplayOptions holds the optional time range of Replay, on Snapshot.FetchedAt.
+type ReplayOptions struct {
+	From time.Time // zero = from the first snapshot
+	To   time.Time // excluded, zero = up to the last snapshot
+}
+
+// ReplayResult holds the outcome of a replay.
+type ReplayResult struct {
+	Files   int // files read
+	Ticks   int // ticks written
+	Skipped int // lines that aren't valid snapshots (ex. partly written when the collector stopped)
+}
+
+// Replay reads the snapshot files in dir oldest first and passes every tick in the time range to write
+// (ex. TickerService.ReplayTick). Stops at the first error.
+func Replay(ctx context.Context, dir string, opts ReplayOptions, write func(ctx context.Context, data *ticker.CMCResponse) error) (ReplayResult, error) {
+	var result ReplayResult
+	files, err := Files(dir)
+	if err != nil {
+		return result, err
+	}
+	for _, f := range files {
+		if !opts.To.IsZero() && !f.Start.Before(opts.To) {
+			break
+		}
+		if err := replayFile(ctx, f.Path, opts, write, &result); err != nil {
+			return result, err
+		}
+		result.Files++
+	}
+	return result, nil
+}
+
+// replayFile passes the ticks of one file to write.
+func replayFile(ctx context.Context, path string, opts ReplayOptions, write func(ctx context.Context, data *ticker.CMCResponse) error, result *ReplayResult) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			var snap Snapshot
+			if jsonErr := json.Unmarshal(b, &snap); jsonErr != nil || snap.Response == nil {
+				result.Skipped++
+			} else if !snap.FetchedAt.Before(opts.From) && (opts.To.IsZero() || snap.FetchedAt.Before(opts.To)) {
+				snap.Response.Disagreements = snap.Disagreements
+				if err := write(ctx, snap.Response); err != nil {
+					return fmt.Errorf("failed to replay %s line %d: %w", path, line, err)
+				}
+				result.Ticks++
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/ticker"
+	"github.com/jdbdev/go-cmc/utils"
+)
+
+// File storage for running without a database (USE_DB=false). Every tick is appended as one JSON line (NDJSON)
+// to a file covering a UTC aligned period (SNAPSHOT_ROTATE), ex. snapshots/ticks-20260102T0300Z.ndjson for the
+// hour starting at 03:00 UTC. Files older than SNAPSHOT_RETENTION are deleted when a new file is started.
+// Snapshots can be written to the database later with Replay (go run ./cmd replay).
+
+const (
+	filePrefix     = "ticks-"
+	fileExt        = ".ndjson"
+	fileTimeLayout = "20060102T1504Z"
+)
+
+// Snapshot is one line of a snapshot file: a tick as returned by the ticker.
+type Snapshot struct {
+	FetchedAt     time.Time             `json:"fetched_at"`
+	Response      *ticker.CMCResponse   `json:"response"`
+	Disagreements []ticker.Disagreement `json:"disagreements,omitempty"` // not part of the CMCResponse JSON
+}
+
+// FileStore is the snapshot file backed ticker.TickStore
+type FileStore struct {
+	dir       string
+	rotate    time.Duration
+	retention time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu      sync.Mutex
+	current string // file written last
+}
+
+// NewFileStore creates a FileStore writing to the snapshot directory, created if missing.
+func NewFileStore(app *config.AppConfig, logger *slog.Logger) (*FileStore, error) {
+	// Validate required dependencies (panic if missing)
+	if app == nil {
+		panic("App configuration required to create FileStore")
+	}
+	// Validate required dependencies (Warn if missing)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	rotate := app.Snapshot.Rotate
+	if rotate < time.Minute {
+		logger.Warn("Invalid snapshot rotation - using 1h", "rotate", rotate)
+		rotate = time.Hour
+	}
+	if err := os.MkdirAll(app.Snapshot.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	logger.Info("FileStore initialized successfully", "dir", app.Snapshot.Dir, "rotate", rotate, "retention", app.Snapshot.Retention)
+
+	return &FileStore{
+		dir:       app.Snapshot.Dir,
+		rotate:    rotate,
+		retention: app.Snapshot.Retention,
+		logger:    logger,
+		now:       time.Now,
+	}, nil
+}
+
+// StoreTick appends a tick to the file of the current period. Starting a new file prunes the expired files.
+func (s *FileStore) StoreTick(ctx context.Context, data *ticker.CMCResponse) error {
+	if data == nil || len(data.Data) == 0 {
+		s.logger.Warn("No CMC data to write to snapshot")
+		return nil
+	}
+	now := s.now().UTC()
+	path := filepath.Join(s.dir, fileName(now.Truncate(s.rotate)))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap := Snapshot{FetchedAt: now, Response: data, Disagreements: data.Disagreements}
+	if err := utils.AppendJSONLine(path, snap); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	s.logger.Info("Snapshot written", "file", path, "coins_count", len(data.Data))
+
+	if path != s.current {
+		s.current = path
+		if _, err := s.Prune(now); err != nil {
+			s.logger.Warn("Failed pruning snapshot files", "error", err)
+		}
+	}
+	return nil
+}
+
+// Prune deletes the files whose period ended more than the retention before now. Returns the number of files deleted.
+func (s *FileStore) Prune(now time.Time) (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	files, err := Files(s.dir)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := now.Add(-s.retention)
+	deleted := 0
+	for _, f := range files {
+		if f.Start.Add(s.rotate).After(cutoff) {
+			continue
+		}
+		if err := os.Remove(f.Path); err != nil {
+			return deleted, fmt.Errorf("failed to delete snapshot file: %w", err)
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		s.logger.Info("Pruned snapshot files", "deleted", deleted, "retention", s.retention)
+	}
+	return deleted, nil
+}
+
+// File is a snapshot file and the start of the period it covers.
+type File struct {
+	Path  string
+	Start time.Time
+}
+
+// Files returns the snapshot files in dir, oldest first. Other files are ignored.
+func Files(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+	var files []File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		start, err := time.Parse(fileTimeLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileExt))
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Path: filepath.Join(dir, name), Start: start})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Start.Before(files[j].Start) })
+	return files, nil
+}
+
+// fileName returns the name of the file of the period starting at start.
+func fileName(start time.Time) string {
+	return filePrefix + start.UTC().Format(fileTimeLayout) + fileExt
+}
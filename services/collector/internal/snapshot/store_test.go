@@ -0,0 +1,113 @@
+package snapshot
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/decimal"
+	"github.com/jdbdev/go-cmc/internal/ticker"
+)
+
+// testTick returns a one coin response with a USD quote last updated at ts
+func testTick(ts time.Time) *ticker.CMCResponse {
+	return &ticker.CMCResponse{Data: map[string]ticker.CoinInfo{"1": {
+		CmcID: 1, Name: "Bitcoin", Symbol: "BTC", Slug: "bitcoin",
+		CirculatingSupply: decimal.FromFloat(19_000_000),
+		LastUpdated:       ticker.Timestamp{Time: ts},
+		Quote: map[string]ticker.CoinQuote{"USD": {
+			Price:       decimal.FromFloat(97000.123456789),
+			LastUpdated: ticker.Timestamp{Time: ts},
+		}},
+	}}}
+}
+
+// newTestStore returns a FileStore in a temporary directory with hourly files kept for 2 hours
+func newTestStore(t *testing.T) *FileStore {
+	app := &config.AppConfig{Snapshot: config.SnapshotSettings{
+		Enabled: true, Dir: t.TempDir(), Rotate: time.Hour, Retention: 2 * time.Hour,
+	}}
+	store, err := NewFileStore(app, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// TestFileStoreRotateAndPrune tests ticks go to hourly files and expired files are deleted on rotation
+func TestFileStoreRotateAndPrune(t *testing.T) {
+	store := newTestStore(t)
+	start := time.Date(2026, 1, 2, 3, 10, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{0, 20 * time.Minute, time.Hour, 3 * time.Hour, 4 * time.Hour} {
+		now := start.Add(offset)
+		store.now = func() time.Time { return now }
+		if err := store.StoreTick(context.Background(), testTick(now)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Files(store.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 03:00 and 04:00 ended more than 2h before 07:10
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.Path))
+	}
+	want := []string{"ticks-20260102T0600Z.ndjson", "ticks-20260102T0700Z.ndjson"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+}
+
+// TestReplay tests ticks are read back oldest first within the time range, with their quotes intact,
+// and a partly written line is skipped
+func TestReplay(t *testing.T) {
+	store := newTestStore(t)
+	start := time.Date(2026, 1, 2, 3, 10, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{0, 30 * time.Minute, time.Hour} {
+		now := start.Add(offset)
+		store.now = func() time.Time { return now }
+		tick := testTick(now)
+		tick.Disagreements = []ticker.Disagreement{{CmcID: 1, Currency: "USD", Deviation: 0.07}}
+		if err := store.StoreTick(context.Background(), tick); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Collector stopped mid write
+	f, err := os.OpenFile(filepath.Join(store.dir, "ticks-20260102T0300Z.ndjson"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"fetched_at":"2026-01-02T03:50:00Z","resp`)
+	f.Close()
+
+	var replayed []*ticker.CMCResponse
+	result, err := Replay(context.Background(), store.dir, ReplayOptions{From: start.Add(time.Minute)},
+		func(ctx context.Context, data *ticker.CMCResponse) error {
+			replayed = append(replayed, data)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (ReplayResult{Files: 2, Ticks: 2, Skipped: 1}) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if len(replayed) != 2 {
+		t.Fatalf("Expected 2 ticks, got %d", len(replayed))
+	}
+	quote := replayed[0].Data["1"].Quote["USD"]
+	if !quote.LastUpdated.Equal(start.Add(30*time.Minute)) || quote.Price.String() != "97000.123456789" {
+		t.Errorf("Unexpected first quote %+v", quote)
+	}
+	if len(replayed[1].Disagreements) != 1 || replayed[1].Disagreements[0].Deviation != 0.07 {
+		t.Errorf("Expected disagreements to be replayed, got %+v", replayed[1].Disagreements)
+	}
+}
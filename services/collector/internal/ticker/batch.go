@@ -0,0 +1,103 @@
+package ticker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Quotes requests are split into batches of CMC IDs so the "id" parameter stays within URL length
+// and per-call limits as tracked_coins grows. CMC charges 1 credit per 100 IDs per call, so batches
+// of 100 cost the same number of credits as a single large request.
+
+// defaultBatchSize is used if CMC_QUOTES_BATCH_SIZE is not set to a valid value.
+const defaultBatchSize = 100
+
+// BatchFailure holds the error for one failed quotes batch.
+type BatchFailure struct {
+	Index int   // batch index in request order
+	IDs   []int // CMC IDs in the batch
+	Err   error
+}
+
+// BatchError is returned by FetchAndDecodeData when one or more batches fail.
+// The response for the successful batches is returned alongside it.
+type BatchError struct {
+	Batches  int // total batches in the request
+	Failures []BatchFailure
+}
+
+// Error summarizes the failed batches.
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		msgs[i] = fmt.Sprintf("batch %d (%d ids): %v", f.Index, len(f.IDs), f.Err)
+	}
+	return fmt.Sprintf("%d of %d quote batches failed: %s", len(e.Failures), e.Batches, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the batch errors for errors.Is and errors.As.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// FailedIDs returns the CMC IDs of all failed batches.
+func (e *BatchError) FailedIDs() []int {
+	var ids []int
+	for _, f := range e.Failures {
+		ids = append(ids, f.IDs...)
+	}
+	return ids
+}
+
+// splitBatches splits IDs into consecutive batches of at most size IDs.
+func splitBatches(ids []int, size int) [][]int {
+	var batches [][]int
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+		batches = append(batches, ids[start:end])
+	}
+	return batches
+}
+
+// mergeBatches merges the successful batch responses into one CMCResponse.
+// Credits are summed, the latest timestamp and longest elapsed time are kept.
+// Returns a nil response if every batch failed, and a *BatchError if any batch failed.
+func mergeBatches(batches [][]int, responses []*CMCResponse, errs []error) (*CMCResponse, *BatchError) {
+	var (
+		merged   *CMCResponse
+		batchErr *BatchError
+	)
+	for i, resp := range responses {
+		if errs[i] != nil || resp == nil {
+			if batchErr == nil {
+				batchErr = &BatchError{Batches: len(batches)}
+			}
+			err := errs[i]
+			if err == nil {
+				err = fmt.Errorf("empty response")
+			}
+			batchErr.Failures = append(batchErr.Failures, BatchFailure{Index: i, IDs: batches[i], Err: err})
+			continue
+		}
+
+		if merged == nil {
+			merged = &CMCResponse{Data: make(map[string]CoinInfo, len(resp.Data))}
+		}
+		for key, coin := range resp.Data {
+			merged.Data[key] = coin
+		}
+		merged.Status.CreditCount += resp.Status.CreditCount
+		merged.Status.Elapsed = max(merged.Status.Elapsed, resp.Status.Elapsed)
+		if resp.Status.Timestamp.After(merged.Status.Timestamp.Time) {
+			merged.Status.Timestamp = resp.Status.Timestamp
+		}
+		if resp.Status.Notice != nil {
+			merged.Status.Notice = resp.Status.Notice
+		}
+	}
+	return merged, batchErr
+}
@@ -0,0 +1,117 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/cmc"
+	"github.com/jdbdev/go-cmc/internal/coins"
+)
+
+// fakeCoins returns a fixed list of tracked coin IDs
+type fakeCoins struct {
+	coins.CoinInterface
+	ids []int
+}
+
+func (f *fakeCoins) GetTrackedCoinIDs(ctx context.Context) ([]int, error) {
+	return f.ids, nil
+}
+
+// TestFetchAndDecodeDataBatches tests IDs are split into batches, merged, and failed batches are reported
+func TestFetchAndDecodeDataBatches(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		ids := strings.Split(r.URL.Query().Get("id"), ",")
+		if len(ids) > 2 {
+			t.Errorf("Expected at most 2 IDs per request, got %d", len(ids))
+		}
+		// Fail the batch containing ID 5
+		if ids[0] == "5" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status": {"error_code": 500, "error_message": "boom"}}`))
+			return
+		}
+		var data []string
+		for _, id := range ids {
+			data = append(data, fmt.Sprintf(`"%s": {"id": %s, "quote": {"USD": {"price": 1}}}`, id, id))
+		}
+		w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 1, "timestamp": "2026-01-02T01:25:55.551Z"},
+			"data": {` + strings.Join(data, ",") + `}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.AppConfig{
+		CMC: config.CMCSettings{
+			APIKey:        "test-key",
+			QuotesURL:     server.URL,
+			QuotesBatch:   2,
+			QuotesWorkers: 2,
+		},
+	}
+	client := cmc.NewClient(cfg, server.Client(), nil, nil)
+	service := NewTickerService(cfg, nil, nil, &fakeCoins{ids: []int{1, 2, 3, 4, 5}}, client, nil, nil)
+
+	resp, err := service.FetchAndDecodeData(context.Background())
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("Expected 3 batch requests, got %d", got)
+	}
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected *BatchError, got %v", err)
+	}
+	if ids := batchErr.FailedIDs(); len(ids) != 1 || ids[0] != 5 {
+		t.Errorf("Expected failed IDs [5], got %v", ids)
+	}
+	if resp == nil || len(resp.Data) != 4 {
+		t.Fatalf("Expected merged response with 4 coins, got %+v", resp)
+	}
+	if resp.Status.CreditCount != 2 {
+		t.Errorf("Expected 2 credits, got %d", resp.Status.CreditCount)
+	}
+}
+
+// TestFetchQuotesNoIDs tests no IDs sends no request and returns an untyped nil error
+func TestFetchQuotesNoIDs(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	cfg := &config.AppConfig{CMC: config.CMCSettings{APIKey: "test-key", QuotesURL: server.URL}}
+	service := NewTickerService(cfg, nil, nil, nil, cmc.NewClient(cfg, server.Client(), nil, nil), nil, nil)
+
+	quotes, err := service.FetchQuotes(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %#v", err)
+	}
+	if len(quotes) != 0 || requests.Load() != 0 {
+		t.Errorf("Expected no quotes and no request, got %d quotes, %d requests", len(quotes), requests.Load())
+	}
+}
+
+// TestEstimateTickCredits tests credits are estimated per batch including extra convert currencies
+func TestEstimateTickCredits(t *testing.T) {
+	cfg := &config.AppConfig{
+		CMC: config.CMCSettings{QuotesBatch: 100, QuotesWorkers: 1, Convert: []string{"USD", "EUR", "BTC"}},
+	}
+	service := NewTickerService(cfg, nil, nil, nil, nil, nil, nil)
+
+	// 250 coins = batches of 100, 100 and 50, each 1 credit + 2 extra currencies
+	if got := service.EstimateTickCredits(250); got != 9 {
+		t.Errorf("Expected 9 credits, got %d", got)
+	}
+	if got := service.EstimateTickCredits(0); got != 0 {
+		t.Errorf("Expected 0 credits, got %d", got)
+	}
+}
@@ -0,0 +1,112 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWriteBuffered is returned by UpdateDB when a tick couldn't be written because the database is unreachable
+// and was kept in the write buffer to be written once the database is back (FlushBuffer).
+var ErrWriteBuffered = errors.New("database unreachable - tick buffered")
+
+// WriteBuffer keeps the ticks that couldn't be written during a database outage, oldest first. It is bounded by
+// the number of quotes held (DB_BUFFER_MAX_QUOTES), the oldest ticks are dropped when a new tick doesn't fit.
+type WriteBuffer struct {
+	flushMu   sync.Mutex // one Flush at a time
+	mu        sync.Mutex
+	ticks     []*CMCResponse
+	quotes    int // quotes held by ticks
+	maxQuotes int // 0 = buffering disabled
+	dropped   int // ticks dropped since the buffer was created
+}
+
+// BufferStatus holds the write buffer counters served on GET /status.
+type BufferStatus struct {
+	Ticks     int `json:"ticks"`
+	Quotes    int `json:"quotes"`
+	MaxQuotes int `json:"max_quotes"`
+	Dropped   int `json:"dropped"`
+}
+
+// NewWriteBuffer creates a WriteBuffer holding at most maxQuotes quotes (0 = disabled).
+func NewWriteBuffer(maxQuotes int) *WriteBuffer {
+	return &WriteBuffer{maxQuotes: max(maxQuotes, 0)}
+}
+
+// Add appends a tick, dropping the oldest ticks to stay within the bound. Returns false if the tick itself was
+// dropped (buffering disabled or a tick bigger than the bound).
+func (b *WriteBuffer) Add(resp *CMCResponse) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := countQuotes(resp)
+	if n > b.maxQuotes {
+		b.dropped++
+		return false
+	}
+	for len(b.ticks) > 0 && b.quotes+n > b.maxQuotes {
+		b.quotes -= countQuotes(b.ticks[0])
+		b.ticks[0] = nil
+		b.ticks = b.ticks[1:]
+		b.dropped++
+	}
+	b.ticks = append(b.ticks, resp)
+	b.quotes += n
+	return true
+}
+
+// Len returns the number of ticks held.
+func (b *WriteBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.ticks)
+}
+
+// Status returns the buffer counters.
+func (b *WriteBuffer) Status() BufferStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BufferStatus{Ticks: len(b.ticks), Quotes: b.quotes, MaxQuotes: b.maxQuotes, Dropped: b.dropped}
+}
+
+// Flush writes the ticks oldest first with write. A tick is removed once written, the first failed write stops
+// the flush and keeps it with the ticks after it. Returns the number of ticks written.
+func (b *WriteBuffer) Flush(ctx context.Context, write func(ctx context.Context, resp *CMCResponse) error) (int, error) {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	written := 0
+	for {
+		b.mu.Lock()
+		if len(b.ticks) == 0 {
+			b.mu.Unlock()
+			return written, nil
+		}
+		resp := b.ticks[0]
+		b.mu.Unlock()
+
+		if err := write(ctx, resp); err != nil {
+			return written, err
+		}
+		written++
+
+		b.mu.Lock()
+		// Add may have dropped the tick while it was written
+		if len(b.ticks) > 0 && b.ticks[0] == resp {
+			b.quotes -= countQuotes(resp)
+			b.ticks[0] = nil
+			b.ticks = b.ticks[1:]
+		}
+		b.mu.Unlock()
+	}
+}
+
+// countQuotes returns the number of quotes (coin and currency pairs) in a response, at least 1 per coin.
+func countQuotes(resp *CMCResponse) int {
+	n := 0
+	for _, coin := range resp.Data {
+		n += max(len(coin.Quote), 1)
+	}
+	return n
+}
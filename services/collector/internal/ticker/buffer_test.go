@@ -0,0 +1,115 @@
+package ticker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// flakyQuoteRepo is a MemoryQuoteRepo failing every latest quote write with err while err is set
+type flakyQuoteRepo struct {
+	*MemoryQuoteRepo
+	err error
+}
+
+func (f *flakyQuoteRepo) UpsertLatest(ctx context.Context, quotes []QuoteRow) (map[QuoteKey]bool, []db.RowError, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return f.MemoryQuoteRepo.UpsertLatest(ctx, quotes)
+}
+
+// TestWriteBufferBound tests the oldest ticks are dropped to stay within the quote bound
+func TestWriteBufferBound(t *testing.T) {
+	buffer := NewWriteBuffer(5)
+	start := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if !buffer.Add(benchResponse(2, start.Add(time.Duration(i)*time.Minute))) {
+			t.Fatalf("Expected tick %d to be buffered", i)
+		}
+	}
+	if got := buffer.Status(); got != (BufferStatus{Ticks: 2, Quotes: 4, MaxQuotes: 5, Dropped: 1}) {
+		t.Errorf("Unexpected buffer status %+v", got)
+	}
+	if buffer.Add(benchResponse(6, start)) {
+		t.Error("Expected a tick bigger than the bound to be dropped")
+	}
+
+	var written []time.Time
+	n, err := buffer.Flush(context.Background(), func(ctx context.Context, resp *CMCResponse) error {
+		written = append(written, resp.Data["900000000"].LastUpdated.Time)
+		return nil
+	})
+	if err != nil || n != 2 || buffer.Len() != 0 {
+		t.Fatalf("Expected 2 ticks flushed and an empty buffer, got %d (%v), %d left", n, err, buffer.Len())
+	}
+	if !written[0].Equal(start.Add(time.Minute)) || !written[1].Equal(start.Add(2*time.Minute)) {
+		t.Errorf("Expected the 2 newest ticks oldest first, got %v", written)
+	}
+
+	if NewWriteBuffer(0).Add(benchResponse(1, start)) {
+		t.Error("Expected a disabled buffer to drop ticks")
+	}
+}
+
+// TestUpdateDBBuffersDuringOutage tests ticks are buffered on connection errors and written oldest first once
+// the database answers again
+func TestUpdateDBBuffersDuringOutage(t *testing.T) {
+	ctx := context.Background()
+	quotes := &flakyQuoteRepo{MemoryQuoteRepo: NewMemoryQuoteRepo(), err: driver.ErrBadConn}
+	cfg := &config.AppConfig{DB: config.DBSettings{BufferMaxQuotes: 100}}
+	service := NewTickerService(cfg, NewMemoryCoinInfoRepo(), quotes, nil, nil, nil, nil)
+
+	t0 := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+
+	// Only connection errors are buffered
+	quotes.err = errors.New("relation \"coin_quote\" does not exist")
+	if err := service.UpdateDB(ctx, benchResponse(2, t0)); err == nil || errors.Is(err, ErrWriteBuffered) {
+		t.Fatalf("Expected a write error, got %v", err)
+	}
+
+	quotes.err = driver.ErrBadConn
+	for _, ts := range []time.Time{t0, t1} {
+		if err := service.UpdateDB(ctx, benchResponse(2, ts)); !errors.Is(err, ErrWriteBuffered) {
+			t.Fatalf("Expected ErrWriteBuffered, got %v", err)
+		}
+	}
+	if got := service.BufferStatus(); got.Ticks != 2 || got.Quotes != 4 {
+		t.Fatalf("Expected 2 ticks and 4 quotes buffered, got %+v", got)
+	}
+
+	quotes.err = nil
+	if n, err := service.FlushBuffer(ctx); err != nil || n != 2 {
+		t.Fatalf("Expected 2 ticks flushed, got %d (%v)", n, err)
+	}
+	history, err := service.GetQuoteHistory(ctx, benchCmcID, quoteCurrency, t0, t1.Add(time.Second))
+	if err != nil || len(history) != 2 {
+		t.Fatalf("Expected 2 history rows, got %d (%v)", len(history), err)
+	}
+	if latest, _ := quotes.Latest(1, quoteCurrency); !latest.Quote.LastUpdated.Equal(t1) {
+		t.Errorf("Expected latest quote at %v, got %v", t1, latest.Quote.LastUpdated)
+	}
+}
+
+// TestUpdateDBDeadlineNotBuffered tests a write failing on its context deadline (ex. a slow CMC request eating the
+// timeout) returns the error instead of buffering the tick as a database outage
+func TestUpdateDBDeadlineNotBuffered(t *testing.T) {
+	quotes := &flakyQuoteRepo{MemoryQuoteRepo: NewMemoryQuoteRepo(), err: context.DeadlineExceeded}
+	cfg := &config.AppConfig{DB: config.DBSettings{BufferMaxQuotes: 100}}
+	service := NewTickerService(cfg, NewMemoryCoinInfoRepo(), quotes, nil, nil, nil, nil)
+
+	t0 := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	err := service.UpdateDB(context.Background(), benchResponse(1, t0))
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrWriteBuffered) {
+		t.Fatalf("Expected the deadline error, got %v", err)
+	}
+	if n := service.BufferStatus().Ticks; n != 0 {
+		t.Errorf("Expected no buffered tick, got %d", n)
+	}
+}
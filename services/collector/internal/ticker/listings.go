@@ -0,0 +1,143 @@
+package ticker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jdbdev/go-cmc/internal/coins"
+	"github.com/jdbdev/go-cmc/internal/mapper"
+)
+
+// Listings mode (TICKER_MODE=listings) fetches the top N coins from /v1/cryptocurrency/listings/latest (CMC_BASE_URL)
+// instead of quotes for tracked_coins. Every coin in the fetched pages is stored. Coins ranked within AUTO_TRACK_TOP_N
+// are added to tracked_coins, so they keep being fetched in quotes mode and by the website.
+// Credits: 1 per 200 coins returned per call plus 1 per convert currency past the first.
+
+// Ticker modes
+const (
+	ModeQuotes   = "quotes"
+	ModeListings = "listings"
+)
+
+// maxListingsPageSize is the CMC maximum limit for one listings request.
+const maxListingsPageSize = 5000
+
+// ListingsResponse holds the response from the CMC listings endpoint (data is a list ordered by the sort option).
+type ListingsResponse struct {
+	Status Status        `json:"status"`
+	Data   []ListingCoin `json:"data"`
+}
+
+// ListingCoin holds a coin of the listings response: the same fields as quotes plus the CMC rank.
+type ListingCoin struct {
+	CoinInfo
+	CmcRank int `json:"cmc_rank"`
+}
+
+// listingsOptions holds the listings mode settings
+type listingsOptions struct {
+	limit     int
+	pageSize  int
+	sort      string
+	sortDir   string
+	autoTrack int // track coins ranked in the top N, 0 = disabled
+}
+
+// fetchListings pages through the listings endpoint until limit coins are fetched (or the last page) and
+// returns them as one CMCResponse with the ranks by CMC ID.
+func (t *TickerService) fetchListings(ctx context.Context) (*CMCResponse, map[int]int, error) {
+	merged := &CMCResponse{Data: make(map[string]CoinInfo)}
+	ranks := make(map[int]int)
+	for start := 1; start <= t.listings.limit; start += t.listings.pageSize {
+		limit := min(t.listings.pageSize, t.listings.limit-start+1)
+		page, err := t.fetchListingsPage(ctx, start, limit)
+		if err != nil {
+			if len(merged.Data) > 0 {
+				// Keep the pages already fetched
+				return merged, ranks, fmt.Errorf("listings page at start %d failed: %w", start, err)
+			}
+			return nil, nil, err
+		}
+
+		merged.Status.CreditCount += page.Status.CreditCount
+		merged.Status.Elapsed = max(merged.Status.Elapsed, page.Status.Elapsed)
+		merged.Status.Timestamp = page.Status.Timestamp
+		for _, coin := range page.Data {
+			merged.Data[strconv.Itoa(coin.CmcID)] = coin.CoinInfo
+			ranks[coin.CmcID] = coin.CmcRank
+		}
+		if len(page.Data) < limit {
+			break // last page
+		}
+	}
+
+	t.logger.Info("Successfully fetched and decoded CMC listings",
+		"coins_count", len(merged.Data),
+		"sort", t.listings.sort,
+		"credit_count", merged.Status.CreditCount)
+	return merged, ranks, nil
+}
+
+// fetchListingsPage gets one page of listings.
+func (t *TickerService) fetchListingsPage(ctx context.Context, start, limit int) (*ListingsResponse, error) {
+	q := url.Values{}
+	q.Add("start", strconv.Itoa(start))
+	q.Add("limit", strconv.Itoa(limit))
+	q.Add("sort", t.listings.sort)
+	q.Add("sort_dir", t.listings.sortDir)
+	q.Add("convert", strings.Join(t.convert, ","))
+	q.Add("aux", "circulating_supply,total_supply,max_supply,volume_24h_reported,cmc_rank")
+
+	// Execute request (retries, status checks and credit accounting in internal/cmc)
+	respBody, err := t.client.GetIfBudget(ctx, "listings", t.baseURL, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var listings ListingsResponse
+	if err := json.Unmarshal(respBody, &listings); err != nil {
+		t.logger.Error("failed to unmarshal listings response", "error", err)
+		return nil, fmt.Errorf("failed to unmarshal listings response: %w", err)
+	}
+	return &listings, nil
+}
+
+// autoTrack adds coins ranked within the auto-track top N that are not in tracked_coins yet.
+// Failures are logged, the listings data is stored either way.
+func (t *TickerService) autoTrack(ctx context.Context, resp *CMCResponse, ranks map[int]int) {
+	if t.listings.autoTrack <= 0 || t.coins == nil {
+		return
+	}
+	// All tracked coins, disabled coins stay disabled
+	tracked, err := t.coins.ListTrackedCoins(ctx, coins.TrackedCoinFilter{})
+	if err != nil {
+		t.logger.Warn("Auto-track skipped - failed to list tracked coins", "error", err)
+		return
+	}
+	isTracked := make(map[int]bool, len(tracked))
+	for _, coin := range tracked {
+		isTracked[coin.CmcID] = true
+	}
+
+	var newCoins []mapper.CmcCoinID
+	for id, rank := range ranks {
+		if rank <= 0 || rank > t.listings.autoTrack || isTracked[id] {
+			continue
+		}
+		coin := resp.Data[strconv.Itoa(id)]
+		newCoins = append(newCoins, mapper.CmcCoinID{ID: id, Rank: &rank, Symbol: coin.Symbol, Name: coin.Name, Slug: coin.Slug, IsActive: 1})
+	}
+	if len(newCoins) == 0 {
+		return
+	}
+	added, err := t.coins.SeedTrackedCoins(ctx, newCoins)
+	if err != nil {
+		t.logger.Warn("Auto-track failed", "error", err, "added", added)
+		return
+	}
+	t.logger.Info("Auto-tracked newly ranked coins", "top_n", t.listings.autoTrack, "added", added)
+}
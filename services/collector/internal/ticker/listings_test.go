@@ -0,0 +1,85 @@
+package ticker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/cmc"
+	"github.com/jdbdev/go-cmc/internal/coins"
+	"github.com/jdbdev/go-cmc/internal/mapper"
+)
+
+// fakeTracker records seeded coins
+type fakeTracker struct {
+	coins.CoinInterface
+	tracked []coins.TrackedCoin
+	seeded  []mapper.CmcCoinID
+}
+
+func (f *fakeTracker) ListTrackedCoins(ctx context.Context, filter coins.TrackedCoinFilter) ([]coins.TrackedCoin, error) {
+	return f.tracked, nil
+}
+
+func (f *fakeTracker) SeedTrackedCoins(ctx context.Context, coins []mapper.CmcCoinID) (int, error) {
+	f.seeded = append(f.seeded, coins...)
+	return len(coins), nil
+}
+
+// TestFetchListings tests listings are paged up to the limit, merged and top ranked coins are auto-tracked
+func TestFetchListings(t *testing.T) {
+	var starts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		starts = append(starts, q.Get("start"))
+		if q.Get("sort") != "market_cap" || q.Get("sort_dir") != "desc" {
+			t.Errorf("Unexpected sort %q %q", q.Get("sort"), q.Get("sort_dir"))
+		}
+		start, _ := strconv.Atoi(q.Get("start"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		var data []string
+		for rank := start; rank < start+limit; rank++ {
+			data = append(data, fmt.Sprintf(`{"id": %d, "symbol": "C%d", "cmc_rank": %d, "quote": {"USD": {"price": 1}}}`, rank*10, rank, rank))
+		}
+		w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 1}, "data": [` + strings.Join(data, ",") + `]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.AppConfig{
+		CMC: config.CMCSettings{
+			BaseURL:          server.URL,
+			TickerMode:       ModeListings,
+			ListingsLimit:    3,
+			ListingsPageSize: 2,
+			ListingsSort:     "market_cap",
+			ListingsSortDir:  "desc",
+			AutoTrackTopN:    2,
+		},
+	}
+	tracker := &fakeTracker{tracked: []coins.TrackedCoin{{CmcID: 10}}}
+	client := cmc.NewClient(cfg, server.Client(), nil, nil)
+	service := NewTickerService(cfg, nil, nil, tracker, client, nil, nil)
+
+	resp, err := service.FetchAndDecodeData(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(starts, ",") != "1,3" {
+		t.Errorf("Expected pages at start 1,3, got %v", starts)
+	}
+	if len(resp.Data) != 3 || resp.Status.CreditCount != 2 {
+		t.Errorf("Expected 3 coins and 2 credits, got %d coins and %d credits", len(resp.Data), resp.Status.CreditCount)
+	}
+	// Rank 1 (ID 10) is already tracked, rank 3 is outside the top 2
+	if len(tracker.seeded) != 1 || tracker.seeded[0].ID != 20 {
+		t.Errorf("Expected only ID 20 auto-tracked, got %+v", tracker.seeded)
+	}
+	if got := service.EstimateTickCredits(0); got != 2 {
+		t.Errorf("Expected 2 estimated credits for 2 pages, got %d", got)
+	}
+}
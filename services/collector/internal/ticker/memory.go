@@ -0,0 +1,166 @@
+package ticker
+
+import (
+	"cmp"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// In-memory CoinInfoRepo and QuoteRepo for tests. They follow the Postgres rules (stale quotes are not written,
+// duplicate history rows are skipped) but never reject rows, and WithTx doesn't roll back (db.NopTx).
+
+// MemoryCoinInfoRepo is an in-memory CoinInfoRepo
+type MemoryCoinInfoRepo struct {
+	db.NopTx
+	mu    sync.Mutex
+	coins map[int]CoinInfo // by CMC ID
+	ids   map[int]int      // coin_info.id by CMC ID
+}
+
+// NewMemoryCoinInfoRepo creates an empty MemoryCoinInfoRepo.
+func NewMemoryCoinInfoRepo() *MemoryCoinInfoRepo {
+	return &MemoryCoinInfoRepo{coins: make(map[int]CoinInfo), ids: make(map[int]int)}
+}
+
+// UpsertCoinInfos stores coins by CMC ID, keeping the stored name, symbol and slug if a coin has none. Like
+// Postgres, a coin older than the stored one is left as is.
+func (r *MemoryCoinInfoRepo) UpsertCoinInfos(ctx context.Context, coins []CoinInfo) (map[int]int, []db.RowError, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make(map[int]int, len(coins))
+	for _, coin := range coins {
+		if stored, ok := r.coins[coin.CmcID]; ok {
+			if !coin.LastUpdated.IsZero() && !stored.LastUpdated.IsZero() && coin.LastUpdated.Before(stored.LastUpdated.Time) {
+				ids[coin.CmcID] = r.ids[coin.CmcID]
+				continue
+			}
+			coin.Name = cmp.Or(coin.Name, stored.Name)
+			coin.Symbol = cmp.Or(coin.Symbol, stored.Symbol)
+			coin.Slug = cmp.Or(coin.Slug, stored.Slug)
+		} else {
+			r.ids[coin.CmcID] = len(r.ids) + 1
+		}
+		r.coins[coin.CmcID] = coin
+		ids[coin.CmcID] = r.ids[coin.CmcID]
+	}
+	return ids, nil, nil
+}
+
+// Get returns a stored coin by CMC ID.
+func (r *MemoryCoinInfoRepo) Get(cmcID int) (CoinInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	coin, ok := r.coins[cmcID]
+	return coin, ok
+}
+
+// MemoryQuoteRepo is an in-memory QuoteRepo
+type MemoryQuoteRepo struct {
+	db.NopTx
+	mu            sync.Mutex
+	latest        map[QuoteKey]QuoteRow
+	history       []QuoteRow
+	historyKeys   map[historyKey]bool
+	disagreements []Disagreement
+}
+
+// historyKey identifies a history row (coin_id, currency, last_updated).
+type historyKey struct {
+	QuoteKey
+	lastUpdated time.Time
+}
+
+// NewMemoryQuoteRepo creates an empty MemoryQuoteRepo.
+func NewMemoryQuoteRepo() *MemoryQuoteRepo {
+	return &MemoryQuoteRepo{latest: make(map[QuoteKey]QuoteRow), historyKeys: make(map[historyKey]bool)}
+}
+
+// UpsertLatest stores quotes that are new or more recent than the stored quote.
+func (r *MemoryQuoteRepo) UpsertLatest(ctx context.Context, quotes []QuoteRow) (map[QuoteKey]bool, []db.RowError, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated := make(map[QuoteKey]bool, len(quotes))
+	for _, q := range quotes {
+		stored, ok := r.latest[q.Key()]
+		if ok && !q.Quote.LastUpdated.IsZero() && !stored.Quote.LastUpdated.IsZero() &&
+			!q.Quote.LastUpdated.After(stored.Quote.LastUpdated.Time) {
+			continue
+		}
+		r.latest[q.Key()] = q
+		updated[q.Key()] = true
+	}
+	return updated, nil, nil
+}
+
+// InsertHistory appends quotes with a last_updated not stored yet.
+func (r *MemoryQuoteRepo) InsertHistory(ctx context.Context, quotes []QuoteRow) (int, []db.RowError, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := 0
+	for _, q := range quotes {
+		key := historyKey{QuoteKey: q.Key(), lastUpdated: q.Quote.LastUpdated.Time}
+		if q.Quote.LastUpdated.IsZero() || r.historyKeys[key] {
+			continue
+		}
+		r.historyKeys[key] = true
+		r.history = append(r.history, q)
+		added++
+	}
+	return added, nil, nil
+}
+
+// InsertDisagreement stores a disagreement.
+func (r *MemoryQuoteRepo) InsertDisagreement(ctx context.Context, d Disagreement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disagreements = append(r.disagreements, d)
+	return nil
+}
+
+// History returns the stored quotes of a CMC ID and currency with last_updated in [from, to), oldest first.
+func (r *MemoryQuoteRepo) History(ctx context.Context, cmcID int, currency string, from, to time.Time) ([]HistoricalQuote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var history []HistoricalQuote
+	for _, row := range r.history {
+		ts := row.Quote.LastUpdated.Time
+		if row.CmcID != cmcID || row.Currency != currency || ts.Before(from) || !ts.Before(to) {
+			continue
+		}
+		q := row.Quote
+		history = append(history, HistoricalQuote{
+			CmcID: row.CmcID, Currency: row.Currency,
+			Price: q.Price, MarketCap: q.MarketCap, FullyDilutedMarketCap: q.FullyDilutedMarketCap, Volume24H: q.Volume24H,
+			PercentChange1H: q.PercentChange1H, PercentChange24h: q.PercentChange24h, PercentChange7d: q.PercentChange7d,
+			LastUpdated:       ts,
+			Volume24HReported: q.Volume24HReported, VolumeChange24H: q.VolumeChange24H,
+			PercentChange30d: q.PercentChange30d, PercentChange60d: q.PercentChange60d, PercentChange90d: q.PercentChange90d,
+			MarketCapDominance: q.MarketCapDominance, Tvl: q.Tvl,
+		})
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].LastUpdated.Before(history[j].LastUpdated) })
+	return history, nil
+}
+
+// Latest returns the stored latest quote of a coin (coin_info.id) and currency.
+func (r *MemoryQuoteRepo) Latest(coinID int, currency string) (QuoteRow, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q, ok := r.latest[QuoteKey{CoinID: coinID, Currency: currency}]
+	return q, ok
+}
+
+// Disagreements returns the stored disagreements.
+func (r *MemoryQuoteRepo) Disagreements() []Disagreement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Disagreement(nil), r.disagreements...)
+}
@@ -0,0 +1,118 @@
+package ticker
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jdbdev/go-cmc/internal/decimal"
+)
+
+// Price providers return normalized quote records so the ticker isn't tied to one API's JSON shape.
+// TickerService is the Coinmarketcap provider, internal/coingecko is the CoinGecko provider.
+// Coins are always identified by CMC ID (tracked_coins), providers map them to their own IDs.
+
+// ProviderCMC is the name of the Coinmarketcap provider.
+const ProviderCMC = "coinmarketcap"
+
+// PriceProvider defines the contract for a source of coin quotes
+type PriceProvider interface {
+	Name() string
+	FetchQuotes(ctx context.Context, cmcIDs []int) ([]Quote, error)
+}
+
+// Quote holds a normalized quote for one coin in one currency from a price provider.
+type Quote struct {
+	Provider              string // provider name (ex. "coinmarketcap", "coingecko")
+	CmcID                 int
+	Symbol                string
+	Name                  string
+	Slug                  string
+	Currency              string // quote currency (ex. "USD")
+	Price                 decimal.Decimal
+	MarketCap             decimal.Decimal
+	FullyDilutedMarketCap decimal.Decimal
+	Volume24H             decimal.Decimal
+	PercentChange1H       float64
+	PercentChange24h      float64
+	PercentChange7d       float64
+	CirculatingSupply     decimal.Decimal
+	TotalSupply           decimal.Decimal
+	LastUpdated           Timestamp // UTC, zero if the provider has no update time
+}
+
+// Name returns the provider name of the CMC ticker.
+func (t *TickerService) Name() string {
+	return ProviderCMC
+}
+
+// FetchQuotes gets quotes from CMC for the CMC IDs and returns them as normalized quotes.
+// Like FetchAndDecodeData, quotes of successful batches are returned with a *BatchError if some failed.
+func (t *TickerService) FetchQuotes(ctx context.Context, cmcIDs []int) ([]Quote, error) {
+	cmcResponse, err := t.fetchIDs(ctx, cmcIDs)
+	if cmcResponse == nil {
+		return nil, err
+	}
+	return QuotesFromCMCResponse(cmcResponse), err
+}
+
+// QuotesFromCMCResponse converts a CMCResponse into normalized quotes, one per coin and currency.
+func QuotesFromCMCResponse(resp *CMCResponse) []Quote {
+	var quotes []Quote
+	for _, coin := range resp.Data {
+		for currency, q := range coin.Quote {
+			quotes = append(quotes, Quote{
+				Provider:              ProviderCMC,
+				CmcID:                 coin.CmcID,
+				Symbol:                coin.Symbol,
+				Name:                  coin.Name,
+				Slug:                  coin.Slug,
+				Currency:              currency,
+				Price:                 q.Price,
+				MarketCap:             q.MarketCap,
+				FullyDilutedMarketCap: q.FullyDilutedMarketCap,
+				Volume24H:             q.Volume24H,
+				PercentChange1H:       q.PercentChange1H,
+				PercentChange24h:      q.PercentChange24h,
+				PercentChange7d:       q.PercentChange7d,
+				CirculatingSupply:     coin.CirculatingSupply,
+				TotalSupply:           coin.TotalSupply,
+				LastUpdated:           q.LastUpdated,
+			})
+		}
+	}
+	return quotes
+}
+
+// CMCResponseFromQuotes converts normalized quotes back into a CMCResponse keyed by CMC ID
+// so quotes from any provider can be stored with UpdateDB.
+func CMCResponseFromQuotes(quotes []Quote) *CMCResponse {
+	resp := &CMCResponse{Data: make(map[string]CoinInfo)}
+	for _, q := range quotes {
+		key := strconv.Itoa(q.CmcID)
+		coin, ok := resp.Data[key]
+		if !ok {
+			coin = CoinInfo{
+				CmcID:             q.CmcID,
+				Name:              q.Name,
+				Symbol:            q.Symbol,
+				Slug:              q.Slug,
+				CirculatingSupply: q.CirculatingSupply,
+				TotalSupply:       q.TotalSupply,
+				LastUpdated:       q.LastUpdated,
+				Quote:             make(map[string]CoinQuote),
+			}
+		}
+		coin.Quote[q.Currency] = CoinQuote{
+			Price:                 q.Price,
+			MarketCap:             q.MarketCap,
+			FullyDilutedMarketCap: q.FullyDilutedMarketCap,
+			Volume24H:             q.Volume24H,
+			PercentChange1H:       q.PercentChange1H,
+			PercentChange24h:      q.PercentChange24h,
+			PercentChange7d:       q.PercentChange7d,
+			LastUpdated:           q.LastUpdated,
+		}
+		resp.Data[key] = coin
+	}
+	return resp
+}
@@ -0,0 +1,184 @@
+package ticker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/jdbdev/go-cmc/internal/decimal"
+)
+
+// Reconciliation of CMC quotes with a secondary price provider (ex. CoinGecko), see FetchAndDecodeData.
+// Only the USD price is reconciled, other quote fields are kept from the provider that answered.
+//   - failover: use CMC, fetch coins missing from the CMC response (failed batches, open breaker) from the secondary
+//   - median: query both, store the median price
+//   - primary: query both, store the CMC price when within tolerance of the secondary, the median otherwise
+// In every mode coins whose prices deviate more than the disagreement threshold are flagged (logged and stored).
+
+// ReconcileMode selects how CMC and secondary provider quotes are combined
+type ReconcileMode string
+
+const (
+	ReconcileFailover ReconcileMode = "failover"
+	ReconcileMedian   ReconcileMode = "median"
+	ReconcilePrimary  ReconcileMode = "primary"
+)
+
+// Disagreement holds a coin whose provider prices deviate more than the disagreement threshold
+type Disagreement struct {
+	CmcID             int
+	Currency          string
+	PrimaryProvider   string
+	PrimaryPrice      decimal.Decimal
+	SecondaryProvider string
+	SecondaryPrice    decimal.Decimal
+	Deviation         float64         // relative to the primary price (0.05 = 5%)
+	Stored            decimal.Decimal // price stored after reconciliation
+}
+
+// parseReconcileMode validates a configured reconcile mode.
+func parseReconcileMode(mode string) (ReconcileMode, error) {
+	switch m := ReconcileMode(mode); m {
+	case ReconcileFailover, ReconcileMedian, ReconcilePrimary:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid reconcile mode %q (failover, median or primary)", mode)
+}
+
+// reconcile combines the CMC response (possibly partial or nil after fetchErr) with quotes from the secondary
+// provider. Returns the combined response and fetchErr, or nil if the secondary covered every missing coin.
+func (t *TickerService) reconcile(ctx context.Context, coinIDs []int, resp *CMCResponse, fetchErr error) (*CMCResponse, error) {
+	if resp == nil {
+		resp = &CMCResponse{Data: make(map[string]CoinInfo)}
+	}
+
+	// Coins to ask the secondary for
+	var missing []int
+	for _, id := range coinIDs {
+		if _, ok := resp.Data[strconv.Itoa(id)]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	request := missing
+	if t.reconcileMode != ReconcileFailover {
+		request = coinIDs
+	}
+	if len(request) == 0 {
+		return resp, fetchErr
+	}
+
+	quotes, err := t.secondary.FetchQuotes(ctx, request)
+	if err != nil {
+		t.logger.Warn("Secondary provider fetch failed", "provider", t.secondary.Name(), "error", err)
+	}
+	secondary := CMCResponseFromQuotes(quotes)
+
+	filled, reconciled := 0, 0
+	for key, coin := range secondary.Data {
+		current, ok := resp.Data[key]
+		if !ok {
+			// Failover: coin missing from CMC
+			resp.Data[key] = coin
+			filled++
+			continue
+		}
+		primaryQuote, okP := current.Quote[quoteCurrency]
+		secondaryQuote, okS := coin.Quote[quoteCurrency]
+		if !okP || !okS {
+			continue
+		}
+		price, d := t.reconcilePrice(coin.CmcID, primaryQuote.Price, secondaryQuote.Price)
+		if d != nil {
+			resp.Disagreements = append(resp.Disagreements, *d)
+			t.logger.Warn("Price providers disagree",
+				"cmc_id", d.CmcID,
+				"primary", d.PrimaryProvider, "primary_price", d.PrimaryPrice,
+				"secondary", d.SecondaryProvider, "secondary_price", d.SecondaryPrice,
+				"deviation", d.Deviation, "stored_price", d.Stored)
+		}
+		if price != primaryQuote.Price { // same normalized text = same price
+			primaryQuote.Price = price
+			current.Quote[quoteCurrency] = primaryQuote
+			reconciled++
+		}
+	}
+
+	// Coins still missing after failover
+	var stillMissing int
+	for _, id := range missing {
+		if _, ok := resp.Data[strconv.Itoa(id)]; !ok {
+			stillMissing++
+		}
+	}
+	t.logger.Info("Quotes reconciled",
+		"mode", t.reconcileMode,
+		"secondary", t.secondary.Name(),
+		"filled_from_secondary", filled,
+		"reconciled", reconciled,
+		"disagreements", len(resp.Disagreements),
+		"missing", stillMissing)
+
+	if len(resp.Data) == 0 {
+		return nil, fetchErr
+	}
+	if fetchErr != nil && stillMissing == 0 {
+		t.logger.Warn("CMC fetch failed - missing coins filled from secondary provider", "error", fetchErr)
+		return resp, nil
+	}
+	return resp, fetchErr
+}
+
+// reconcilePrice returns the price to store for a coin quoted by both providers and a Disagreement
+// if the prices deviate more than the threshold. Deviations and medians are calculated in float64,
+// a price kept from one provider stays exact.
+func (t *TickerService) reconcilePrice(cmcID int, primary, secondary decimal.Decimal) (decimal.Decimal, *Disagreement) {
+	// Zero or negative prices are not prices, use the other provider
+	if primary.Sign() <= 0 {
+		return secondary, nil
+	}
+	if secondary.Sign() <= 0 {
+		return primary, nil
+	}
+
+	p, s := primary.Float64(), secondary.Float64()
+	deviation := math.Abs(p-s) / p
+	price := primary
+	switch t.reconcileMode {
+	case ReconcileMedian:
+		price = decimal.FromFloat(median([]float64{p, s}))
+	case ReconcilePrimary:
+		if deviation > t.tolerance {
+			price = decimal.FromFloat(median([]float64{p, s}))
+		}
+	}
+
+	if t.disagreeThreshold <= 0 || deviation <= t.disagreeThreshold {
+		return price, nil
+	}
+	return price, &Disagreement{
+		CmcID:             cmcID,
+		Currency:          quoteCurrency,
+		PrimaryProvider:   ProviderCMC,
+		PrimaryPrice:      primary,
+		SecondaryProvider: t.secondary.Name(),
+		SecondaryPrice:    secondary,
+		Deviation:         deviation,
+		Stored:            price,
+	}
+}
+
+// median returns the median of the values (mean of the two middle values for an even count).
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
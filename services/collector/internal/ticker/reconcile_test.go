@@ -0,0 +1,128 @@
+package ticker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/cmc"
+	"github.com/jdbdev/go-cmc/internal/decimal"
+)
+
+// fakeProvider returns fixed USD prices by CMC ID
+type fakeProvider struct {
+	prices    map[int]float64
+	requested []int
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) FetchQuotes(ctx context.Context, cmcIDs []int) ([]Quote, error) {
+	f.requested = cmcIDs
+	var quotes []Quote
+	for _, id := range cmcIDs {
+		if price, ok := f.prices[id]; ok {
+			quotes = append(quotes, Quote{Provider: "fake", CmcID: id, Currency: "USD", Price: decimal.FromFloat(price)})
+		}
+	}
+	return quotes, nil
+}
+
+// newReconcileService returns a ticker for CMC IDs 1, 2 and 3 with a CMC stand-in quoting every ID at 100,
+// except ID 3 which always fails.
+func newReconcileService(t *testing.T, mode string, secondary PriceProvider) *TickerService {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id == "3" {
+			w.Write([]byte(`{"status": {"error_code": 400, "error_message": "bad id"}}`))
+			return
+		}
+		w.Write([]byte(fmt.Sprintf(`{"status": {"error_code": 0}, "data": {"%s": {"id": %s, "quote": {"USD": {"price": 100}}}}}`, id, id)))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.AppConfig{
+		CMC: config.CMCSettings{QuotesURL: server.URL, QuotesBatch: 1, QuotesWorkers: 1},
+		Providers: config.ProviderSettings{
+			ReconcileMode:     mode,
+			Tolerance:         0.01,
+			DisagreeThreshold: 0.05,
+		},
+	}
+	client := cmc.NewClient(cfg, server.Client(), nil, nil)
+	return NewTickerService(cfg, nil, nil, &fakeCoins{ids: []int{1, 2, 3}}, client, secondary, nil)
+}
+
+// TestReconcileFailover tests only coins missing from CMC are fetched from the secondary provider
+func TestReconcileFailover(t *testing.T) {
+	secondary := &fakeProvider{prices: map[int]float64{1: 200, 3: 50}}
+	service := newReconcileService(t, "failover", secondary)
+
+	resp, err := service.FetchAndDecodeData(context.Background())
+	if err != nil {
+		t.Fatalf("Expected missing coin filled from secondary, got %v", err)
+	}
+	if len(secondary.requested) != 1 || secondary.requested[0] != 3 {
+		t.Errorf("Expected secondary to be asked for [3], got %v", secondary.requested)
+	}
+	if got := resp.Data["1"].Quote["USD"].Price.String(); got != "100" {
+		t.Errorf("Expected CMC price 100 for coin 1, got %v", got)
+	}
+	if got := resp.Data["3"].Quote["USD"].Price.String(); got != "50" {
+		t.Errorf("Expected secondary price 50 for coin 3, got %v", got)
+	}
+	if len(resp.Disagreements) != 0 {
+		t.Errorf("Expected no disagreements in failover mode, got %+v", resp.Disagreements)
+	}
+}
+
+// TestReconcileModes tests stored prices and disagreement flags per reconcile mode
+func TestReconcileModes(t *testing.T) {
+	tests := []struct {
+		mode      string
+		secondary float64
+		want      float64
+		flagged   bool
+	}{
+		{"median", 100.5, 100.25, false},
+		{"median", 120, 110, true},
+		{"primary", 100.5, 100, false}, // within 1% tolerance
+		{"primary", 103, 101.5, false}, // outside tolerance, below threshold
+		{"primary", 120, 110, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%v", tt.mode, tt.secondary), func(t *testing.T) {
+			secondary := &fakeProvider{prices: map[int]float64{1: tt.secondary, 2: 100, 3: 100}}
+			service := newReconcileService(t, tt.mode, secondary)
+
+			resp, err := service.FetchAndDecodeData(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := resp.Data["1"].Quote["USD"].Price.Float64(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Expected price %v, got %v", tt.want, got)
+			}
+			flagged := len(resp.Disagreements) == 1 && resp.Disagreements[0].CmcID == 1
+			if flagged != tt.flagged {
+				t.Errorf("Expected flagged=%v, got disagreements %+v", tt.flagged, resp.Disagreements)
+			}
+		})
+	}
+}
+
+// TestMedian tests odd and even value counts
+func TestMedian(t *testing.T) {
+	if got := median([]float64{3, 1, 2}); got != 2 {
+		t.Errorf("Expected 2, got %v", got)
+	}
+	if got := median([]float64{4, 1, 2, 3}); got != 2.5 {
+		t.Errorf("Expected 2.5, got %v", got)
+	}
+	if got := median(nil); got != 0 {
+		t.Errorf("Expected 0 for no values, got %v", got)
+	}
+}
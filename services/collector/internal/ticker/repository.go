@@ -0,0 +1,52 @@
+package ticker
+
+import (
+	"context"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// Repositories the ticker writes quotes to, injected by NewTickerService. Postgres implementations are in
+// store.go, in-memory implementations for tests in memory.go. Writes of a tick are grouped with WithTx.
+
+// CoinInfoRepo stores coin_info rows.
+type CoinInfoRepo interface {
+	db.TxRunner
+	// UpsertCoinInfos upserts coins by CMC ID and returns coin_info.id by CMC ID. Rejects index into coins.
+	UpsertCoinInfos(ctx context.Context, coins []CoinInfo) (map[int]int, []db.RowError, error)
+}
+
+// QuoteRepo stores the latest quotes (coin_quote), the quote history (coin_quote_history) and quote disagreements.
+type QuoteRepo interface {
+	db.TxRunner
+	// UpsertLatest upserts the latest quote per coin and currency and returns the quotes written. Quotes whose
+	// last_updated isn't more recent than the stored quote (stale API cache) are not written. Rejects index into quotes.
+	UpsertLatest(ctx context.Context, quotes []QuoteRow) (map[QuoteKey]bool, []db.RowError, error)
+	// InsertHistory appends quotes to the history and returns the number of rows added. Quotes already stored for
+	// their last_updated, or without last_updated, are skipped. Rejects index into quotes.
+	InsertHistory(ctx context.Context, quotes []QuoteRow) (int, []db.RowError, error)
+	InsertDisagreement(ctx context.Context, d Disagreement) error
+	// History returns the stored quotes of a CMC ID and currency with last_updated in [from, to), oldest first.
+	History(ctx context.Context, cmcID int, currency string, from, to time.Time) ([]HistoricalQuote, error)
+}
+
+// QuoteKey identifies a latest quote (coin_quote row).
+type QuoteKey struct {
+	CoinID   int
+	Currency string
+}
+
+// QuoteRow is a quote to store for a coin (coin_info.id) and currency.
+type QuoteRow struct {
+	CmcID    int
+	Symbol   string
+	CoinID   int
+	Currency string
+	Quote    CoinQuote
+}
+
+// Key returns the QuoteKey of the row.
+func (r QuoteRow) Key() QuoteKey {
+	return QuoteKey{CoinID: r.CoinID, Currency: r.Currency}
+}
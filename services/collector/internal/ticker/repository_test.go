@@ -0,0 +1,147 @@
+package ticker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/internal/decimal"
+)
+
+// TestUpdateDBMemoryRepos tests a tick write through the repositories: coin_info IDs are reused, stale quotes
+// don't replace the latest quote or add history, and GetQuoteHistory reads the history back.
+func TestUpdateDBMemoryRepos(t *testing.T) {
+	ctx := context.Background()
+	coinInfo := NewMemoryCoinInfoRepo()
+	quotes := NewMemoryQuoteRepo()
+	service := NewTickerService(&config.AppConfig{}, coinInfo, quotes, nil, nil, nil, nil)
+
+	t0 := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	for _, resp := range []*CMCResponse{
+		benchResponse(2, t0),
+		benchResponse(2, t1),
+		benchResponse(2, t0), // stale, older than the stored quotes
+	} {
+		if err := service.UpdateDB(ctx, resp); err != nil {
+			t.Fatalf("UpdateDB: %v", err)
+		}
+	}
+
+	coin, ok := coinInfo.Get(benchCmcID)
+	if !ok || coin.Symbol != "B0" {
+		t.Fatalf("Expected coin_info for CMC ID %d, got %+v (found %v)", benchCmcID, coin, ok)
+	}
+	latest, ok := quotes.Latest(1, quoteCurrency)
+	if !ok || !latest.Quote.LastUpdated.Equal(t1) {
+		t.Errorf("Expected latest quote at %v, got %+v (found %v)", t1, latest.Quote.LastUpdated, ok)
+	}
+
+	history, err := service.GetQuoteHistory(ctx, benchCmcID, quoteCurrency, t0, t1.Add(time.Second))
+	if err != nil {
+		t.Fatalf("GetQuoteHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history rows, got %d", len(history))
+	}
+	if !history[0].LastUpdated.Equal(t0) || !history[1].LastUpdated.Equal(t1) {
+		t.Errorf("Expected history at %v and %v, got %v and %v", t0, t1, history[0].LastUpdated, history[1].LastUpdated)
+	}
+	if want := decimal.FromFloat(0.123456789); history[0].Price.String() != want.String() {
+		t.Errorf("Expected price %s, got %s", want, history[0].Price)
+	}
+}
+
+// TestUpdateDBNotConnected tests UpdateDB fails without repositories instead of dropping the tick silently
+func TestUpdateDBNotConnected(t *testing.T) {
+	service := NewTickerService(&config.AppConfig{}, nil, nil, nil, nil, nil, nil)
+	if err := service.UpdateDB(context.Background(), benchResponse(1, time.Now())); err == nil {
+		t.Error("Expected error without repositories, got nil")
+	}
+}
+
+// TestReplayTickBackfill tests a replayed tick older than the stored quote adds history without replacing the
+// latest quote
+func TestReplayTickBackfill(t *testing.T) {
+	ctx := context.Background()
+	quotes := NewMemoryQuoteRepo()
+	service := NewTickerService(&config.AppConfig{}, NewMemoryCoinInfoRepo(), quotes, nil, nil, nil, nil)
+
+	t0 := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	if err := service.UpdateDB(ctx, benchResponse(1, t1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ReplayTick(ctx, benchResponse(1, t0)); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := service.GetQuoteHistory(ctx, benchCmcID, quoteCurrency, t0, t1.Add(time.Second))
+	if err != nil || len(history) != 2 {
+		t.Fatalf("Expected 2 history rows, got %d (%v)", len(history), err)
+	}
+	if latest, _ := quotes.Latest(1, quoteCurrency); !latest.Quote.LastUpdated.Equal(t1) {
+		t.Errorf("Expected latest quote at %v, got %v", t1, latest.Quote.LastUpdated)
+	}
+}
+
+// TestReplayTickKeepsNewerCoinInfo tests a replayed tick older than the stored coin_info row doesn't roll it back
+func TestReplayTickKeepsNewerCoinInfo(t *testing.T) {
+	ctx := context.Background()
+	coinInfo := NewMemoryCoinInfoRepo()
+	service := NewTickerService(&config.AppConfig{}, coinInfo, NewMemoryQuoteRepo(), nil, nil, nil, nil)
+
+	t0 := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	newer := benchResponse(1, t1)
+	coin := newer.Data[strconv.Itoa(benchCmcID)]
+	coin.Name, coin.CirculatingSupply = "Bench renamed", decimal.FromFloat(19_500_000)
+	newer.Data[strconv.Itoa(benchCmcID)] = coin
+	if err := service.UpdateDB(ctx, newer); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ReplayTick(ctx, benchResponse(1, t0)); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, ok := coinInfo.Get(benchCmcID)
+	if !ok {
+		t.Fatal("Expected coin_info row")
+	}
+	if stored.Name != "Bench renamed" || !stored.LastUpdated.Equal(t1) || stored.CirculatingSupply.String() != "19500000" {
+		t.Errorf("Expected the newer coin_info to be kept, got %q at %v (supply %s)", stored.Name, stored.LastUpdated, stored.CirculatingSupply)
+	}
+}
+
+// TestUpdateDBWriteFailure tests a tick is stored on success, and a failed write returns its error without
+// storing or buffering the tick
+func TestUpdateDBWriteFailure(t *testing.T) {
+	ctx := context.Background()
+	quotes := &flakyQuoteRepo{MemoryQuoteRepo: NewMemoryQuoteRepo()}
+	cfg := &config.AppConfig{DB: config.DBSettings{BufferMaxQuotes: 100}}
+	service := NewTickerService(cfg, NewMemoryCoinInfoRepo(), quotes, nil, nil, nil, nil)
+
+	t0 := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	if err := service.UpdateDB(ctx, benchResponse(1, t0)); err != nil {
+		t.Fatalf("UpdateDB: %v", err)
+	}
+	if latest, ok := quotes.Latest(1, quoteCurrency); !ok || !latest.Quote.LastUpdated.Equal(t0) {
+		t.Fatalf("Expected latest quote at %v, got %+v (found %v)", t0, latest.Quote.LastUpdated, ok)
+	}
+
+	writeErr := errors.New("permission denied for table coin_quote")
+	quotes.err = writeErr
+	t1 := t0.Add(time.Minute)
+	if err := service.UpdateDB(ctx, benchResponse(1, t1)); !errors.Is(err, writeErr) || errors.Is(err, ErrWriteBuffered) {
+		t.Fatalf("Expected the write error, got %v", err)
+	}
+	if latest, _ := quotes.Latest(1, quoteCurrency); !latest.Quote.LastUpdated.Equal(t0) {
+		t.Errorf("Expected latest quote to stay at %v, got %v", t0, latest.Quote.LastUpdated)
+	}
+	if n := service.BufferStatus().Ticks; n != 0 {
+		t.Errorf("Expected no buffered tick, got %d", n)
+	}
+}
@@ -17,40 +17,71 @@ package ticker
 import (
 	"context"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"encoding/json"
 
 	"github.com/jdbdev/go-cmc/config"
+	"github.com/jdbdev/go-cmc/db"
+	"github.com/jdbdev/go-cmc/internal/cmc"
 	"github.com/jdbdev/go-cmc/internal/coins"
+	"github.com/jdbdev/go-cmc/internal/credits"
 )
 
-var client = &http.Client{}
-
-// TEMP MAP ONLY. USE DB TABLE FOR ID MAP.
-var coinIDMap []string = []string{"1", "1027", "5994", "20947", "2010", "8916"}
+// TickStore stores the ticks fetched by the ticker: the database (TickerService.StoreTick) or snapshot files
+// when the database is disabled (internal/snapshot).
+type TickStore interface {
+	StoreTick(ctx context.Context, data *CMCResponse) error
+}
 
 type TickerInterface interface {
 	FetchAndDecodeData(ctx context.Context) (*CMCResponse, error)
-	UpdateDB() error
+	UpdateDB(ctx context.Context, data *CMCResponse) error
+	StoreTick(ctx context.Context, data *CMCResponse) error
+	ReplayTick(ctx context.Context, data *CMCResponse) error
+	FlushBuffer(ctx context.Context) (int, error)
+	BufferStatus() BufferStatus
+	GetQuoteHistory(ctx context.Context, cmcID int, currency string, from, to time.Time) ([]HistoricalQuote, error)
+	EstimateTickCredits(coinCount int) int
 }
 
 type TickerService struct {
-	apiKey    string
-	baseURL   string
-	quotesURL string
-	client    *http.Client
-	logger    *slog.Logger
-	coins     coins.CoinInterface
+	baseURL     string
+	quotesURL   string
+	batchSize   int      // CMC IDs per quotes request
+	concurrency int      // concurrent quotes requests
+	convert     []string // quote currencies (CoinInfo.Quote keys)
+	mode        string   // ModeQuotes or ModeListings
+	listings    listingsOptions
+	client      *cmc.Client
+	logger      *slog.Logger
+	coins       coins.CoinInterface
+	coinInfo    CoinInfoRepo // coin_info writes
+	quotes      QuoteRepo    // coin_quote, coin_quote_history and quote_disagreement reads and writes
+	buffer      *WriteBuffer // ticks not written during a database outage (buffer.go)
+
+	mu         sync.Mutex
+	trackedIDs []int // last tracked CMC IDs read, used while tracked_coins can't be read
+
+	// Secondary price provider, nil = CMC only (reconcile.go)
+	secondary         PriceProvider
+	reconcileMode     ReconcileMode
+	tolerance         float64
+	disagreeThreshold float64
 	// data    []TickerData // Add a field to store the decoded data
 }
 
 // NewTickerService creates a new instance of the TickerService struct
-func NewTickerService(app *config.AppConfig, coinService coins.CoinInterface, logger *slog.Logger) *TickerService {
+// coinInfo and quotes are the repositories quotes are stored in (repository.go).
+// secondary is an optional second price provider used for failover and reconciliation (nil = CMC only).
+func NewTickerService(app *config.AppConfig, coinInfo CoinInfoRepo, quotes QuoteRepo, coinService coins.CoinInterface,
+	client *cmc.Client, secondary PriceProvider, logger *slog.Logger) *TickerService {
 	// Validate required dependencies (panic if missing)
 	if app == nil {
 		panic("App configuration required to create TickerService")
@@ -59,72 +90,217 @@ func NewTickerService(app *config.AppConfig, coinService coins.CoinInterface, lo
 	if logger == nil {
 		logger = slog.Default()
 	}
-	if app.CMC.APIKey == "" {
-		logger.Warn("No API key provided - requires API key")
-	}
 	if app.CMC.QuotesURL == "" {
 		logger.Warn("No quotes URL provided - requires quotes URL")
 	}
 	if client == nil {
-		logger.Warn("No HTTP client provided - requires HTTP client")
+		logger.Warn("No CMC client provided - requires CMC client")
+	}
+	if coinInfo == nil || quotes == nil {
+		logger.Warn("No coin info or quote repository provided - requires repositories to store quotes")
+	}
+	batchSize, concurrency := app.CMC.QuotesBatch, app.CMC.QuotesWorkers
+	if batchSize <= 0 {
+		logger.Warn("Invalid quotes batch size - using default", "batch_size", batchSize)
+		batchSize = defaultBatchSize
+	}
+	if concurrency <= 0 {
+		logger.Warn("Invalid quotes concurrency - using 1", "concurrency", concurrency)
+		concurrency = 1
+	}
+	mode := app.CMC.TickerMode
+	if mode != ModeQuotes && mode != ModeListings {
+		logger.Warn("Invalid ticker mode - using quotes", "mode", mode)
+		mode = ModeQuotes
+	}
+	listings := listingsOptions{
+		limit:     app.CMC.ListingsLimit,
+		pageSize:  app.CMC.ListingsPageSize,
+		sort:      app.CMC.ListingsSort,
+		sortDir:   app.CMC.ListingsSortDir,
+		autoTrack: app.CMC.AutoTrackTopN,
+	}
+	if mode == ModeListings {
+		if app.CMC.BaseURL == "" {
+			logger.Warn("No listings URL (CMC_BASE_URL) provided - requires listings URL")
+		}
+		if listings.limit <= 0 {
+			logger.Warn("Invalid listings limit - using 100", "limit", listings.limit)
+			listings.limit = 100
+		}
+		if listings.pageSize <= 0 || listings.pageSize > maxListingsPageSize {
+			logger.Warn("Invalid listings page size - using CMC maximum", "page_size", listings.pageSize)
+			listings.pageSize = maxListingsPageSize
+		}
+		if listings.sort == "" {
+			listings.sort = "market_cap"
+		}
+		if listings.sortDir != "asc" && listings.sortDir != "desc" {
+			listings.sortDir = "desc"
+		}
+	}
+	convert := app.CMC.Convert
+	if len(convert) == 0 {
+		logger.Warn("No convert currencies provided - using USD")
+		convert = []string{quoteCurrency}
+	}
+	reconcileMode, err := parseReconcileMode(app.Providers.ReconcileMode)
+	if err != nil && secondary != nil {
+		logger.Warn("Invalid reconcile mode - using failover", "error", err)
 	}
-	logger.Info("TickerService initialized successfully")
+	if err != nil {
+		reconcileMode = ReconcileFailover
+	}
+	if secondary != nil {
+		logger.Info("Secondary price provider enabled", "provider", secondary.Name(), "reconcile_mode", reconcileMode)
+	}
+	logger.Info("TickerService initialized successfully", "mode", mode)
 
 	// Return struct with values
 	return &TickerService{
-		apiKey:    app.CMC.APIKey,
-		baseURL:   app.CMC.BaseURL,
-		quotesURL: app.CMC.QuotesURL,
-		client:    client,
-		logger:    logger,
-		coins:     coinService,
+		baseURL:           app.CMC.BaseURL,
+		quotesURL:         app.CMC.QuotesURL,
+		batchSize:         batchSize,
+		concurrency:       concurrency,
+		convert:           convert,
+		mode:              mode,
+		listings:          listings,
+		client:            client,
+		logger:            logger,
+		coins:             coinService,
+		coinInfo:          coinInfo,
+		quotes:            quotes,
+		buffer:            NewWriteBuffer(app.DB.BufferMaxQuotes),
+		secondary:         secondary,
+		reconcileMode:     reconcileMode,
+		tolerance:         app.Providers.Tolerance,
+		disagreeThreshold: app.Providers.DisagreeThreshold,
 	}
 }
 
-// FetchAndDecodeData gets and decodes data from CMC
+// FetchAndDecodeData gets and decodes data from CMC for all tracked coins, or the top N listings in listings mode.
+// IDs are split into batches fetched with bounded concurrency and merged into one CMCResponse.
+// If some batches fail the merged response of the successful batches is returned with a *BatchError.
+// With a secondary provider the response is combined with its quotes (see reconcile.go).
 func (t *TickerService) FetchAndDecodeData(ctx context.Context) (*CMCResponse, error) {
+	if t.mode == ModeListings {
+		return t.fetchAndDecodeListings(ctx)
+	}
 
-	// Create new request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", t.quotesURL, nil)
+	// Get CMC IDs to fetch from tracked_coins table (source of truth)
+	coinIDs, err := t.trackedCoinIDs(ctx)
 	if err != nil {
-		t.logger.Error("failed to create request", "error", err)
+		return nil, fmt.Errorf("failed to get tracked coin IDs: %w", err)
+	}
+	if len(coinIDs) == 0 {
+		return nil, fmt.Errorf("no tracked coins to fetch")
+	}
+
+	cmcResponse, err := t.fetchIDs(ctx, coinIDs)
+	if t.secondary != nil {
+		return t.reconcile(ctx, coinIDs, cmcResponse, err)
+	}
+	return cmcResponse, err
+}
+
+// trackedCoinIDs returns the tracked CMC IDs. If tracked_coins can't be read (database outage) the last IDs
+// read are used so quotes are still fetched and buffered.
+func (t *TickerService) trackedCoinIDs(ctx context.Context) ([]int, error) {
+	coinIDs, err := t.coins.GetTrackedCoinIDs(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		if len(t.trackedIDs) == 0 || !db.IsConnError(err) {
+			return nil, err
+		}
+		t.logger.Warn("Failed getting tracked coin IDs - using last known IDs", "count", len(t.trackedIDs), "error", err)
+		return t.trackedIDs, nil
+	}
+	t.trackedIDs = coinIDs
+	return coinIDs, nil
+}
+
+// fetchAndDecodeListings gets the top N listings and auto-tracks newly ranked coins.
+// With a secondary provider the listed coins are reconciled like tracked coins.
+func (t *TickerService) fetchAndDecodeListings(ctx context.Context) (*CMCResponse, error) {
+	cmcResponse, ranks, err := t.fetchListings(ctx)
+	if cmcResponse == nil {
 		return nil, err
 	}
+	t.autoTrack(ctx, cmcResponse, ranks)
+
+	if t.secondary != nil {
+		coinIDs := make([]int, 0, len(ranks))
+		for id := range ranks {
+			coinIDs = append(coinIDs, id)
+		}
+		return t.reconcile(ctx, coinIDs, cmcResponse, err)
+	}
+	return cmcResponse, err
+}
+
+// fetchIDs gets and decodes quotes from CMC for a list of CMC IDs (batched). No IDs returns nil, nil.
+func (t *TickerService) fetchIDs(ctx context.Context, coinIDs []int) (*CMCResponse, error) {
+	if len(coinIDs) == 0 {
+		return nil, nil
+	}
+	batches := splitBatches(coinIDs, t.batchSize)
+	responses := make([]*CMCResponse, len(batches))
+	errs := make([]error, len(batches))
+
+	// Fetch batches, at most t.concurrency requests in flight
+	sem := make(chan struct{}, t.concurrency)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch []int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			responses[i], errs[i] = t.fetchBatch(ctx, batch)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	cmcResponse, batchErr := mergeBatches(batches, responses, errs)
+	if cmcResponse == nil {
+		if batchErr != nil {
+			return nil, batchErr
+		}
+		return nil, nil
+	}
+
+	t.logger.Info("Successfully fetched and decoded CMC data",
+		"coins_count", len(cmcResponse.Data),
+		"batches", len(batches),
+		"credit_count", cmcResponse.Status.CreditCount)
+	if batchErr != nil {
+		t.logger.Warn("Some quote batches failed", "failed", len(batchErr.Failures), "batches", len(batches))
+		return cmcResponse, batchErr
+	}
+	return cmcResponse, nil
+}
+
+// fetchBatch gets and decodes quotes from CMC for one batch of IDs
+func (t *TickerService) fetchBatch(ctx context.Context, coinIDs []int) (*CMCResponse, error) {
 
 	// Build query parameters
 	q := url.Values{}
 
-	// Collect all IDs from the map
-	q.Add("id", strings.Join(coinIDMap, ",")) // Join IDs with commas and add to query
-	q.Add("convert", "USD")
+	// Collect all IDs in this batch
+	q.Add("id", joinIDs(coinIDs))                  // Join IDs with commas and add to query
+	q.Add("convert", strings.Join(t.convert, ",")) // each currency past the first costs 1 extra credit
 
 	// Only get requested fields (automatically get price, market_cap, volume_24h, etc. in "quotes"):
 	// Available aux fields: num_market_pairs, cmc_rank, date_added, tags, platform, max_supply,
 	// circulating_supply, total_supply, market_cap_by_total_supply, volume_24h_reported,
 	// volume_7d, volume_7d_reported, volume_30d, volume_30d_reported, is_active, is_fiat
-	q.Add("aux", "circulating_supply,total_supply,volume_24h_reported")
+	q.Add("aux", "circulating_supply,total_supply,max_supply,volume_24h_reported")
 
-	// Set headers
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-CMC_PRO_API_KEY", t.apiKey)
-
-	// Add query parameters to URL
-	req.URL.RawQuery = q.Encode()
-
-	// Execute request
-	resp, err := t.client.Do(req)
+	// Execute request (retries, status checks and credit accounting in internal/cmc)
+	respBody, err := t.client.Get(ctx, "quotes", t.quotesURL, q)
 	if err != nil {
-		t.logger.Error("HTTP request failed", "error", err, "url", req.URL.String())
-		return nil, err
-	} else {
-		t.logger.Info("HTTP request successful", "status", resp.Status, "url", req.URL.String())
-	}
-	defer resp.Body.Close()
-
-	// Read and debug response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		t.logger.Error("failed to read response body", "error", err)
 		return nil, err
 	}
 
@@ -135,26 +311,275 @@ func (t *TickerService) FetchAndDecodeData(ctx context.Context) (*CMCResponse, e
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Check for API errors
-	if cmcResponse.Status.ErrorCode != 0 {
-		errorMsg := "API error"
-		if cmcResponse.Status.ErrorMessage != nil {
-			errorMsg = *cmcResponse.Status.ErrorMessage
-		}
-		t.logger.Error("Coinmarketcap API returned error",
-			"error_code", cmcResponse.Status.ErrorCode,
-			"error_message", errorMsg,
-			"credit_count", cmcResponse.Status.CreditCount)
-		return nil, fmt.Errorf("API error (code %d): %s", cmcResponse.Status.ErrorCode, errorMsg)
-	}
-
-	t.logger.Info("Successfully fetched and decoded CMC data",
+	t.logger.Debug("Fetched quotes batch",
 		"coins_count", len(cmcResponse.Data),
 		"credit_count", cmcResponse.Status.CreditCount)
 	return &cmcResponse, nil
 }
 
-// UpdateDB updates the database with data from CMC
-func (t *TickerService) UpdateDB() error {
+// EstimateTickCredits returns the CMC credits a tick for coinCount coins costs with the configured
+// batch size and convert currencies (see credits.QuotesCallCost and credits.ListingsCallCost).
+func (t *TickerService) EstimateTickCredits(coinCount int) int {
+	total := 0
+	if t.mode == ModeListings {
+		// coinCount is ignored, every tick fetches the top N listings
+		for remaining := t.listings.limit; remaining > 0; remaining -= t.listings.pageSize {
+			total += credits.ListingsCallCost(min(remaining, t.listings.pageSize), len(t.convert))
+		}
+		return total
+	}
+	for remaining := coinCount; remaining > 0; remaining -= t.batchSize {
+		total += credits.QuotesCallCost(min(remaining, t.batchSize), len(t.convert))
+	}
+	return total
+}
+
+// UpdateDB updates the database with data from CMC.
+// All coins in the response are written in a single transaction: coin_info is upserted by cmc_id,
+// coin_quote by coin_id and each quote is appended to coin_quote_history. Quotes whose last_updated hasn't moved
+// since the stored quote are skipped so a stale API cache doesn't add history rows. Rows are written in bulk
+// (db/bulk.go). Coins and quotes with values that don't fit their columns are rejected and logged (validate.go),
+// as are rows rejected by the database, any other failure rolls back the whole update. If the database is
+// unreachable the tick is kept in the write buffer and ErrWriteBuffered is returned.
+func (t *TickerService) UpdateDB(ctx context.Context, data *CMCResponse) error {
+	if data == nil || len(data.Data) == 0 {
+		t.logger.Warn("No CMC data to update database with")
+		return nil
+	}
+	if t.coinInfo == nil || t.quotes == nil {
+		return db.ErrNotConnected
+	}
+
+	// Ticks buffered during an outage are written first so quotes reach the database oldest first
+	if t.buffer.Len() > 0 {
+		if _, err := t.FlushBuffer(ctx); err != nil {
+			return t.bufferTick(data, err)
+		}
+	}
+
+	stats, err := t.write(ctx, data, false)
+	if db.IsConnError(err) {
+		return t.bufferTick(data, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	t.logger.Info("Database updated with CMC data",
+		"coins_count", len(data.Data),
+		"quotes_count", stats.quotes,
+		"history_count", stats.history,
+		"rejected", stats.rejected,
+		"stale_skipped", stats.stale,
+		"disagreements", len(data.Disagreements))
+	return nil
+}
+
+// FlushBuffer writes the ticks buffered during a database outage, oldest first. A buffered tick rejected because
+// of its data is logged and dropped, any other failure stops the flush. Returns the number of ticks written.
+func (t *TickerService) FlushBuffer(ctx context.Context) (int, error) {
+	if t.coinInfo == nil || t.quotes == nil {
+		return 0, db.ErrNotConnected
+	}
+	pending := t.buffer.Len()
+	if pending == 0 {
+		return 0, nil
+	}
+
+	written, err := t.buffer.Flush(ctx, func(ctx context.Context, resp *CMCResponse) error {
+		_, err := t.write(ctx, resp, false)
+		if db.IsDataError(err) {
+			t.logger.Error("Dropped buffered tick", "coins_count", len(resp.Data), "error", err)
+			return nil
+		}
+		return err
+	})
+	t.logger.Info("Flushed buffered ticks", "written", written, "pending", pending, "remaining", t.buffer.Len())
+	return written, err
+}
+
+// BufferStatus returns the write buffer counters.
+func (t *TickerService) BufferStatus() BufferStatus {
+	return t.buffer.Status()
+}
+
+// StoreTick implements TickStore with UpdateDB.
+func (t *TickerService) StoreTick(ctx context.Context, data *CMCResponse) error {
+	return t.UpdateDB(ctx, data)
+}
+
+// ReplayTick writes a tick fetched earlier (ex. snapshot files, internal/snapshot) in one transaction. Unlike
+// UpdateDB quotes older than the stored latest quote are still added to coin_quote_history, and a failed write
+// is returned instead of buffered.
+func (t *TickerService) ReplayTick(ctx context.Context, data *CMCResponse) error {
+	if t.coinInfo == nil || t.quotes == nil {
+		return db.ErrNotConnected
+	}
+	stats, err := t.write(ctx, data, true)
+	if err != nil {
+		return err
+	}
+	t.logger.Info("Replayed tick",
+		"coins_count", len(data.Data),
+		"quotes_count", stats.quotes,
+		"history_count", stats.history,
+		"rejected", stats.rejected,
+		"stale_skipped", stats.stale)
 	return nil
 }
+
+// bufferTick keeps a tick that couldn't be written because of the connection error err.
+func (t *TickerService) bufferTick(data *CMCResponse, err error) error {
+	if !t.buffer.Add(data) {
+		return fmt.Errorf("database unreachable - tick dropped (DB_BUFFER_MAX_QUOTES): %w", err)
+	}
+	return fmt.Errorf("%w: %w", ErrWriteBuffered, err)
+}
+
+// write writes a response in one transaction (see writeResponse for backfill).
+func (t *TickerService) write(ctx context.Context, data *CMCResponse, backfill bool) (writeStats, error) {
+	var stats writeStats
+	err := t.quotes.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		stats, err = t.writeResponse(ctx, data, backfill)
+		return err
+	})
+	return stats, err
+}
+
+// writeStats holds the row counts of a write.
+type writeStats struct {
+	quotes   int // coin_quote rows inserted or updated
+	history  int // coin_quote_history rows added
+	rejected int // coins and quotes rejected by validation or the database
+	stale    int // quotes skipped because last_updated hasn't moved
+}
+
+// writeResponse writes a response to the repositories: coin_info, coin_quote, coin_quote_history and quote_disagreement.
+// With backfill quotes older than the stored latest quote are still added to coin_quote_history.
+func (t *TickerService) writeResponse(ctx context.Context, data *CMCResponse, backfill bool) (writeStats, error) {
+	var stats writeStats
+
+	// Reject values that don't fit their NUMERIC columns before they reach the database.
+	// Coins are written in CMC ID order so concurrent writers lock rows in the same order.
+	coinList := make([]CoinInfo, 0, len(data.Data))
+	for _, coin := range data.Data {
+		if err := validateCoinInfo(coin); err != nil {
+			t.logger.Warn("Rejected coin - value out of range", "cmc_id", coin.CmcID, "symbol", coin.Symbol, "error", err)
+			stats.rejected++
+			continue
+		}
+		coinList = append(coinList, coin)
+	}
+	sort.Slice(coinList, func(i, j int) bool { return coinList[i].CmcID < coinList[j].CmcID })
+
+	coinIDs, rejects, err := t.coinInfo.UpsertCoinInfos(ctx, coinList)
+	if err != nil {
+		return stats, err
+	}
+	for _, r := range rejects {
+		coin := coinList[r.Index]
+		t.logger.Warn("Rejected coin by database", "cmc_id", coin.CmcID, "symbol", coin.Symbol, "error", r.Err)
+		stats.rejected++
+	}
+
+	// One coin_quote row and history row per currency
+	var quotes []QuoteRow
+	for _, coin := range coinList {
+		coinID, ok := coinIDs[coin.CmcID]
+		if !ok {
+			continue // rejected
+		}
+		if len(coin.Quote) == 0 {
+			t.logger.Warn("No quote in response for coin", "cmc_id", coin.CmcID)
+			continue
+		}
+		for currency, quote := range coin.Quote {
+			if err := validateQuote(quote); err != nil {
+				t.logger.Warn("Rejected quote - value out of range",
+					"cmc_id", coin.CmcID, "symbol", coin.Symbol, "currency", currency, "error", err)
+				stats.rejected++
+				continue
+			}
+			quotes = append(quotes, QuoteRow{CmcID: coin.CmcID, Symbol: coin.Symbol, CoinID: coinID, Currency: currency, Quote: quote})
+		}
+	}
+	sort.Slice(quotes, func(i, j int) bool {
+		if quotes[i].CoinID != quotes[j].CoinID {
+			return quotes[i].CoinID < quotes[j].CoinID
+		}
+		return quotes[i].Currency < quotes[j].Currency
+	})
+
+	updated, rejects, err := t.quotes.UpsertLatest(ctx, quotes)
+	if err != nil {
+		return stats, err
+	}
+	rejected := make(map[int]bool, len(rejects))
+	for _, r := range rejects {
+		q := quotes[r.Index]
+		t.logger.Warn("Rejected quote by database", "cmc_id", q.CmcID, "symbol", q.Symbol, "currency", q.Currency, "error", r.Err)
+		rejected[r.Index] = true
+		stats.rejected++
+	}
+
+	// last_updated hasn't moved since the stored quote, don't add a history row for a cached quote.
+	// A backfill (replayed snapshots) adds every quote, history rows already stored are skipped.
+	historyRows := make([]QuoteRow, 0, len(updated))
+	for i, q := range quotes {
+		if rejected[i] {
+			continue
+		}
+		if updated[q.Key()] {
+			stats.quotes++
+		} else {
+			stats.stale++
+			if !backfill {
+				continue
+			}
+		}
+		historyRows = append(historyRows, q)
+	}
+
+	added, rejects, err := t.quotes.InsertHistory(ctx, historyRows)
+	if err != nil {
+		return stats, err
+	}
+	for _, r := range rejects {
+		q := historyRows[r.Index]
+		t.logger.Warn("Rejected history row by database", "cmc_id", q.CmcID, "currency", q.Currency, "error", r.Err)
+	}
+	stats.history = added
+
+	for _, d := range data.Disagreements {
+		if err := validateDisagreement(d); err != nil {
+			t.logger.Warn("Rejected disagreement - value out of range", "cmc_id", d.CmcID, "error", err)
+			continue
+		}
+		if err := t.quotes.InsertDisagreement(ctx, d); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// GetQuoteHistory returns the stored quotes for a coin (by CMC ID) in a currency (ex. "USD") with last_updated
+// in [from, to), oldest first.
+func (t *TickerService) GetQuoteHistory(ctx context.Context, cmcID int, currency string, from, to time.Time) ([]HistoricalQuote, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid time range: from %s must be before to %s", from, to)
+	}
+	if t.quotes == nil {
+		return nil, db.ErrNotConnected
+	}
+	return t.quotes.History(ctx, cmcID, strings.ToUpper(currency), from, to)
+}
+
+// joinIDs joins CMC IDs into a comma separated string for the "id" query parameter (ex. "1,1027,2010")
+func joinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
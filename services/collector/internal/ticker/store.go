@@ -0,0 +1,311 @@
+package ticker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jdbdev/go-cmc/db"
+)
+
+// SQL for the quote tables written by UpdateDB and read by GetQuoteHistory. Tables are created by db/migrations/001
+// to 003, 008 to 013. coin_info is upserted by cmc_id and coin_quote by (coin_id, currency) (one latest quote per
+// coin and currency), only if last_updated moved forward. coin_quote_history is append-only, duplicate
+// (coin_id, currency, last_updated) rows are ignored. coin_info rows older than the stored row (ex. replayed
+// snapshots) are left as is. Timestamps are TIMESTAMPTZ written as UTC.
+// Coins and quotes are written with the bulk path (db/bulk.go), many rows per statement.
+var (
+	coinInfoUpsert = db.BulkUpsert{
+		Table: "coin_info",
+		Columns: []string{"cmc_id", "name", "symbol", "slug", "circulating_supply", "total_supply", "last_updated",
+			"max_supply", "infinite_supply", "self_reported_circulating_supply", "self_reported_market_cap", "tvl_ratio"},
+		OnConflict: `
+			ON CONFLICT (cmc_id) DO UPDATE SET
+				name = COALESCE(NULLIF(EXCLUDED.name, ''), coin_info.name),
+				symbol = COALESCE(NULLIF(EXCLUDED.symbol, ''), coin_info.symbol),
+				slug = COALESCE(NULLIF(EXCLUDED.slug, ''), coin_info.slug),
+				circulating_supply = EXCLUDED.circulating_supply,
+				total_supply = EXCLUDED.total_supply,
+				last_updated = EXCLUDED.last_updated,
+				max_supply = EXCLUDED.max_supply,
+				infinite_supply = COALESCE(EXCLUDED.infinite_supply, coin_info.infinite_supply),
+				self_reported_circulating_supply = EXCLUDED.self_reported_circulating_supply,
+				self_reported_market_cap = EXCLUDED.self_reported_market_cap,
+				tvl_ratio = EXCLUDED.tvl_ratio,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE EXCLUDED.last_updated IS NULL OR coin_info.last_updated IS NULL
+				OR EXCLUDED.last_updated >= coin_info.last_updated`,
+		Returning: "cmc_id, id",
+	}
+
+	// IDs of the coin_info rows left as is by coinInfoUpsert, which returns only the rows it wrote
+	selectCoinInfoIDsSQL = `
+		SELECT cmc_id, id FROM coin_info WHERE cmc_id = ANY(string_to_array($1, ',')::int[])`
+
+	coinQuoteUpsert = db.BulkUpsert{
+		Table:   "coin_quote",
+		Columns: quoteColumns,
+		OnConflict: `
+			ON CONFLICT (coin_id, currency) DO UPDATE SET
+				price = EXCLUDED.price,
+				market_cap = EXCLUDED.market_cap,
+				fully_diluted_market_cap = EXCLUDED.fully_diluted_market_cap,
+				volume_24h = EXCLUDED.volume_24h,
+				percent_change_1h = EXCLUDED.percent_change_1h,
+				percent_change_24h = EXCLUDED.percent_change_24h,
+				percent_change_7d = EXCLUDED.percent_change_7d,
+				last_updated = EXCLUDED.last_updated,
+				volume_24h_reported = EXCLUDED.volume_24h_reported,
+				volume_change_24h = EXCLUDED.volume_change_24h,
+				percent_change_30d = EXCLUDED.percent_change_30d,
+				percent_change_60d = EXCLUDED.percent_change_60d,
+				percent_change_90d = EXCLUDED.percent_change_90d,
+				market_cap_dominance = EXCLUDED.market_cap_dominance,
+				tvl = EXCLUDED.tvl,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE EXCLUDED.last_updated IS NULL OR coin_quote.last_updated IS NULL
+				OR EXCLUDED.last_updated > coin_quote.last_updated`,
+		Returning: "coin_id, currency", // only quotes inserted or moved forward
+	}
+
+	quoteHistoryInsert = db.BulkUpsert{
+		Table:      "coin_quote_history",
+		Columns:    quoteColumns,
+		OnConflict: "ON CONFLICT (coin_id, currency, last_updated) DO NOTHING",
+	}
+
+	// quoteColumns are the columns of coin_quote and coin_quote_history, in quoteValues order
+	quoteColumns = []string{"coin_id", "currency", "price", "market_cap", "fully_diluted_market_cap", "volume_24h",
+		"percent_change_1h", "percent_change_24h", "percent_change_7d", "last_updated",
+		"volume_24h_reported", "volume_change_24h", "percent_change_30d", "percent_change_60d", "percent_change_90d",
+		"market_cap_dominance", "tvl"}
+)
+
+const (
+	insertDisagreementSQL = `
+		INSERT INTO quote_disagreement (cmc_id, currency, primary_provider, primary_price,
+			secondary_provider, secondary_price, deviation, stored_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	selectQuoteHistorySQL = `
+		SELECT ci.cmc_id, h.currency, h.price, COALESCE(h.market_cap, 0), COALESCE(h.fully_diluted_market_cap, 0),
+			COALESCE(h.volume_24h, 0), COALESCE(h.percent_change_1h, 0), COALESCE(h.percent_change_24h, 0),
+			COALESCE(h.percent_change_7d, 0), h.last_updated,
+			h.volume_24h_reported, h.volume_change_24h, h.percent_change_30d, h.percent_change_60d,
+			h.percent_change_90d, h.market_cap_dominance, h.tvl
+		FROM coin_quote_history h
+		JOIN coin_info ci ON ci.id = h.coin_id
+		WHERE ci.cmc_id = $1 AND h.currency = $2 AND h.last_updated >= $3 AND h.last_updated < $4
+		ORDER BY h.last_updated ASC`
+)
+
+// quoteCurrency is the default convert currency and the CoinInfo.Quote map key reconciled with secondary providers.
+const quoteCurrency = "USD"
+
+// PostgresCoinInfoRepo is the coin_info table backed CoinInfoRepo
+type PostgresCoinInfoRepo struct {
+	database *db.Database
+}
+
+// NewPostgresCoinInfoRepo creates a CoinInfoRepo on database (nil returns db.ErrNotConnected on every call).
+func NewPostgresCoinInfoRepo(database *db.Database) *PostgresCoinInfoRepo {
+	return &PostgresCoinInfoRepo{database: database}
+}
+
+// PostgresQuoteRepo is the coin_quote, coin_quote_history and quote_disagreement tables backed QuoteRepo
+type PostgresQuoteRepo struct {
+	database *db.Database
+}
+
+// NewPostgresQuoteRepo creates a QuoteRepo on database (nil returns db.ErrNotConnected on every call).
+func NewPostgresQuoteRepo(database *db.Database) *PostgresQuoteRepo {
+	return &PostgresQuoteRepo{database: database}
+}
+
+// WithTx runs fn in a transaction shared by the repositories on the same database.
+func (r *PostgresCoinInfoRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.database.WithTx(ctx, fn)
+}
+
+// WithTx runs fn in a transaction shared by the repositories on the same database.
+func (r *PostgresQuoteRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.database.WithTx(ctx, fn)
+}
+
+// quoteValues returns the row values in quoteColumns order.
+func quoteValues(r QuoteRow) []any {
+	q := r.Quote
+	return []any{r.CoinID, r.Currency, q.Price, q.MarketCap, q.FullyDilutedMarketCap, q.Volume24H,
+		q.PercentChange1H, q.PercentChange24h, q.PercentChange7d, q.LastUpdated,
+		q.Volume24HReported, q.VolumeChange24H, q.PercentChange30d, q.PercentChange60d,
+		q.PercentChange90d, q.MarketCapDominance, q.Tvl}
+}
+
+// UpsertCoinInfos upserts coin_info rows and returns coin_info.id by CMC ID.
+func (r *PostgresCoinInfoRepo) UpsertCoinInfos(ctx context.Context, coins []CoinInfo) (map[int]int, []db.RowError, error) {
+	rows := make([][]any, len(coins))
+	for i, coin := range coins {
+		rows[i] = []any{coin.CmcID, coin.Name, coin.Symbol, coin.Slug,
+			coin.CirculatingSupply, coin.TotalSupply, coin.LastUpdated,
+			coin.MaxSupply, coin.InfiniteSupply, coin.SelfReportedCirculatingSupply, coin.SelfReportedMarketCap, coin.TvlRatio}
+	}
+
+	ids := make(map[int]int, len(coins))
+	var rejects []db.RowError
+	err := r.WithTx(ctx, func(ctx context.Context) error {
+		tx, _ := db.TxFromContext(ctx)
+		result, err := coinInfoUpsert.Exec(ctx, tx, rows, func(res *sql.Rows) error {
+			var cmcID, id int
+			if err := res.Scan(&cmcID, &id); err != nil {
+				return err
+			}
+			ids[cmcID] = id
+			return nil
+		})
+		rejects = result.Rejected
+		if err != nil {
+			return err
+		}
+		return selectSkippedCoinIDs(ctx, tx, coins, rejects, ids)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("upsert coin_info: %w", err)
+	}
+	return ids, rejects, nil
+}
+
+// selectSkippedCoinIDs adds to ids the coin_info.id of the coins neither written nor rejected (older than the
+// stored row).
+func selectSkippedCoinIDs(ctx context.Context, q db.Querier, coins []CoinInfo, rejects []db.RowError, ids map[int]int) error {
+	rejected := make(map[int]bool, len(rejects))
+	for _, r := range rejects {
+		rejected[r.Index] = true
+	}
+	var skipped []string
+	for i, coin := range coins {
+		if _, ok := ids[coin.CmcID]; !ok && !rejected[i] {
+			skipped = append(skipped, strconv.Itoa(coin.CmcID))
+		}
+	}
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	rows, err := q.QueryContext(ctx, selectCoinInfoIDsSQL, strings.Join(skipped, ","))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cmcID, id int
+		if err := rows.Scan(&cmcID, &id); err != nil {
+			return err
+		}
+		ids[cmcID] = id
+	}
+	return rows.Err()
+}
+
+// UpsertLatest upserts the latest coin_quote rows and returns the quotes written (inserted or moved forward).
+func (r *PostgresQuoteRepo) UpsertLatest(ctx context.Context, quotes []QuoteRow) (map[QuoteKey]bool, []db.RowError, error) {
+	rows := make([][]any, len(quotes))
+	for i, q := range quotes {
+		rows[i] = quoteValues(q)
+	}
+
+	updated := make(map[QuoteKey]bool, len(quotes))
+	var rejects []db.RowError
+	err := r.WithTx(ctx, func(ctx context.Context) error {
+		tx, _ := db.TxFromContext(ctx)
+		result, err := coinQuoteUpsert.Exec(ctx, tx, rows, func(res *sql.Rows) error {
+			var key QuoteKey
+			if err := res.Scan(&key.CoinID, &key.Currency); err != nil {
+				return err
+			}
+			updated[key] = true
+			return nil
+		})
+		rejects = result.Rejected
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("upsert coin_quote: %w", err)
+	}
+	return updated, rejects, nil
+}
+
+// InsertHistory appends quotes to coin_quote_history and returns the number of rows added.
+func (r *PostgresQuoteRepo) InsertHistory(ctx context.Context, quotes []QuoteRow) (int, []db.RowError, error) {
+	rows := make([][]any, 0, len(quotes))
+	index := make([]int, 0, len(quotes)) // row -> quotes index, to report rejects against quotes
+	for i, q := range quotes {
+		if q.Quote.LastUpdated.IsZero() {
+			continue
+		}
+		rows = append(rows, quoteValues(q))
+		index = append(index, i)
+	}
+
+	var result db.BulkResult
+	err := r.WithTx(ctx, func(ctx context.Context) error {
+		tx, _ := db.TxFromContext(ctx)
+		var err error
+		result, err = quoteHistoryInsert.Exec(ctx, tx, rows, nil)
+		return err
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("insert coin_quote_history: %w", err)
+	}
+	for i := range result.Rejected {
+		result.Rejected[i].Index = index[result.Rejected[i].Index]
+	}
+	return int(result.Affected), result.Rejected, nil
+}
+
+// InsertDisagreement stores a coin flagged by quote reconciliation.
+func (r *PostgresQuoteRepo) InsertDisagreement(ctx context.Context, d Disagreement) error {
+	q, err := r.database.Querier(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, insertDisagreementSQL,
+		d.CmcID, d.Currency, d.PrimaryProvider, d.PrimaryPrice,
+		d.SecondaryProvider, d.SecondaryPrice, d.Deviation, d.Stored,
+	); err != nil {
+		return fmt.Errorf("insert quote_disagreement (cmc_id %d): %w", d.CmcID, err)
+	}
+	return nil
+}
+
+// History reads the stored quotes for a CMC ID and currency within [from, to), oldest first.
+func (r *PostgresQuoteRepo) History(ctx context.Context, cmcID int, currency string, from, to time.Time) ([]HistoricalQuote, error) {
+	q, err := r.database.Querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, selectQuoteHistorySQL, cmcID, currency, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("select coin_quote_history (cmc_id %d): %w", cmcID, err)
+	}
+	defer rows.Close()
+
+	var history []HistoricalQuote
+	for rows.Next() {
+		var h HistoricalQuote
+		if err := rows.Scan(&h.CmcID, &h.Currency, &h.Price, &h.MarketCap, &h.FullyDilutedMarketCap, &h.Volume24H,
+			&h.PercentChange1H, &h.PercentChange24h, &h.PercentChange7d, &h.LastUpdated,
+			&h.Volume24HReported, &h.VolumeChange24H, &h.PercentChange30d, &h.PercentChange60d,
+			&h.PercentChange90d, &h.MarketCapDominance, &h.Tvl); err != nil {
+			return nil, fmt.Errorf("scan coin_quote_history row: %w", err)
+		}
+		h.LastUpdated = h.LastUpdated.UTC()
+		history = append(history, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate coin_quote_history rows: %w", err)
+	}
+	return history, nil
+}
@@ -1,46 +1,89 @@
 package ticker
 
+import (
+	"time"
+
+	"github.com/jdbdev/go-cmc/internal/cmc"
+	"github.com/jdbdev/go-cmc/internal/decimal"
+)
+
 // All JSON fields that can be null in CMC API response are pointers allowing null values to avoid
 // unmarshalling errors or setting zero values instead of nil.
 // Always check documentation when adding new fields.
 // Always check for nil if trying to dereference a pointer to avoid runtime errors (panic).
+// Prices, market caps, volumes and supplies are decimal.Decimal to keep micro-cap prices (1e-9) and
+// quadrillion supplies exact from JSON to NUMERIC columns. Percent changes are float64.
 
 // CMCResponse holds the response from the CMC API.
 type CMCResponse struct {
 	Status Status              `json:"status"`
 	Data   map[string]CoinInfo `json:"data"`
-}
 
-// Status holds the response status from CMC API.
-type Status struct {
-	Timestamp    string  `json:"timestamp"`
-	ErrorCode    int     `json:"error_code"`
-	ErrorMessage *string `json:"error_message"`
-	Elapsed      int     `json:"elapsed"`
-	CreditCount  int     `json:"credit_count"`
-	Notice       *string `json:"notice"`
+	Disagreements []Disagreement `json:"-"` // set when reconciled with a secondary provider, stored by UpdateDB
 }
 
+// Status holds the response status from CMC API (shared with the mapper in internal/cmc).
+type Status = cmc.Status
+
+// Timestamp is a CMC timestamp parsed as UTC (zero time for null or missing values).
+type Timestamp = cmc.Timestamp
+
 // CoinInfo holds the coin related information from CMC API, including CoinQuote data.
 type CoinInfo struct {
 	CmcID             int                  `json:"id"` // CMC ID is recommended by CMC API documentation
 	Name              string               `json:"name"`
 	Symbol            string               `json:"symbol"`
 	Slug              string               `json:"slug"`
-	CirculatingSupply float64              `json:"circulating_supply"`
-	TotalSupply       float64              `json:"total_supply"`
-	LastUpdated       string               `json:"last_updated"`
-	Quote             map[string]CoinQuote `json:"quote"` // map key is "USD" from CMC API Response
+	CirculatingSupply decimal.Decimal      `json:"circulating_supply"`
+	TotalSupply       decimal.Decimal      `json:"total_supply"`
+	LastUpdated       Timestamp            `json:"last_updated"`
+	Quote             map[string]CoinQuote `json:"quote"` // map key is the convert currency (ex. "USD", "EUR", "BTC")
+
+	MaxSupply                     *decimal.Decimal `json:"max_supply"`      // null for coins without a max supply
+	InfiniteSupply                *bool            `json:"infinite_supply"` // nil if the provider doesn't report it
+	SelfReportedCirculatingSupply *decimal.Decimal `json:"self_reported_circulating_supply"`
+	SelfReportedMarketCap         *decimal.Decimal `json:"self_reported_market_cap"`
+	TvlRatio                      *float64         `json:"tvl_ratio"`
 }
 
-// CoinQuote holds the quote data for a coin from CMC API. Only uses USD for map key in CoinInfo.
+// CoinQuote holds the quote data for a coin from CMC API in one currency (CMC_CONVERT), stored per currency in coin_quote.
 type CoinQuote struct {
-	Price                 float64 `json:"price"`
-	MarketCap             float64 `json:"market_cap"`
-	FullyDilutedMarketCap float64 `json:"fully_diluted_market_cap"`
-	Volume24H             float64 `json:"volume_24h"`
-	PercentChange1H       float64 `json:"percent_change_1h"`
-	PercentChange24h      float64 `json:"percent_change_24h"`
-	PercentChange7d       float64 `json:"percent_change_7d"`
-	LastUpdated           string  `json:"last_updated"`
+	Price                 decimal.Decimal `json:"price"`
+	MarketCap             decimal.Decimal `json:"market_cap"`
+	FullyDilutedMarketCap decimal.Decimal `json:"fully_diluted_market_cap"`
+	Volume24H             decimal.Decimal `json:"volume_24h"`
+	PercentChange1H       float64         `json:"percent_change_1h"`
+	PercentChange24h      float64         `json:"percent_change_24h"`
+	PercentChange7d       float64         `json:"percent_change_7d"`
+	LastUpdated           Timestamp       `json:"last_updated"`
+
+	Volume24HReported  *decimal.Decimal `json:"volume_24h_reported"` // aux volume_24h_reported
+	VolumeChange24H    *float64         `json:"volume_change_24h"`
+	PercentChange30d   *float64         `json:"percent_change_30d"` // null for coins listed less than 30 days
+	PercentChange60d   *float64         `json:"percent_change_60d"`
+	PercentChange90d   *float64         `json:"percent_change_90d"`
+	MarketCapDominance *float64         `json:"market_cap_dominance"`
+	Tvl                *decimal.Decimal `json:"tvl"`
+}
+
+// HistoricalQuote holds a stored quote for a coin at a point in time. Row in DB coin_quote_history table.
+type HistoricalQuote struct {
+	CmcID                 int
+	Currency              string
+	Price                 decimal.Decimal
+	MarketCap             decimal.Decimal
+	FullyDilutedMarketCap decimal.Decimal
+	Volume24H             decimal.Decimal
+	PercentChange1H       float64
+	PercentChange24h      float64
+	PercentChange7d       float64
+	LastUpdated           time.Time // CMC last_updated for the quote (UTC)
+
+	Volume24HReported  *decimal.Decimal // nil when not stored (NULL)
+	VolumeChange24H    *float64
+	PercentChange30d   *float64
+	PercentChange60d   *float64
+	PercentChange90d   *float64
+	MarketCapDominance *float64
+	Tvl                *decimal.Decimal
 }
@@ -4,6 +4,7 @@ package ticker
 // This file contains a "medium" level of struct definitions with most fields.
 // It is kept as reference only for later inclusion when needed.
 // The actual structs used in the codebase are in types.go (minimal version).
+// Supply, 30/60/90 day change, reported volume, dominance and TVL fields are promoted to types.go as pointers.
 
 // NOTE: Structs use "Ref" suffix to avoid conflicts. Change this in types.go if needed.
 // This file is for reference only and won't be used in compilation.
@@ -0,0 +1,65 @@
+package ticker
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jdbdev/go-cmc/internal/decimal"
+)
+
+// TestDecodeSampleResponse tests the promoted reference fields decode from a real CMC response, null values as nil
+func TestDecodeSampleResponse(t *testing.T) {
+	body, err := os.ReadFile("../../sample_response.json")
+	if err != nil {
+		t.Skipf("sample response not available: %v", err)
+	}
+	var resp CMCResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("Failed to decode sample response: %v", err)
+	}
+
+	btc, ok := resp.Data["1"]
+	if !ok {
+		t.Fatal("Expected bitcoin in sample response")
+	}
+	if btc.InfiniteSupply == nil || *btc.InfiniteSupply {
+		t.Errorf("Expected infinite_supply false, got %v", btc.InfiniteSupply)
+	}
+	if btc.SelfReportedCirculatingSupply != nil || btc.TvlRatio != nil {
+		t.Errorf("Expected null self reported supply and TVL ratio as nil")
+	}
+	usd := btc.Quote["USD"]
+	if usd.Volume24HReported == nil || usd.PercentChange30d == nil || usd.PercentChange90d == nil {
+		t.Errorf("Expected volume_24h_reported, percent_change_30d and percent_change_90d to be set")
+	}
+	// Decimal fields keep the JSON text exactly
+	if got := usd.Price.String(); got != "88615.1532707756" {
+		t.Errorf("Expected exact price 88615.1532707756, got %s", got)
+	}
+	if usd.Tvl != nil {
+		t.Errorf("Expected null tvl as nil, got %v", *usd.Tvl)
+	}
+}
+
+// TestValidateQuote tests out of range values are rejected with the field name
+func TestValidateQuote(t *testing.T) {
+	price, _ := decimal.New("1.234e-9")
+	quote := CoinQuote{Price: price}
+	if err := validateQuote(quote); err != nil {
+		t.Errorf("Expected micro-cap price to fit, got %v", err)
+	}
+
+	quote.MarketCap, _ = decimal.New("1e40")
+	err := validateQuote(quote)
+	if !errors.Is(err, decimal.ErrOutOfRange) || !strings.Contains(err.Error(), "market_cap") {
+		t.Errorf("Expected market_cap out of range, got %v", err)
+	}
+
+	supply, _ := decimal.New("589247000000000")
+	if err := validateCoinInfo(CoinInfo{CirculatingSupply: supply}); err != nil {
+		t.Errorf("Expected quadrillion supply to fit, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package ticker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jdbdev/go-cmc/internal/decimal"
+)
+
+// Values are checked against their NUMERIC columns (db/migrations/011) before UpdateDB writes them.
+// A value that doesn't fit rejects its coin (coin_info) or its quote (one currency) instead of failing the
+// whole transaction.
+
+// numeric holds the precision and scale of a NUMERIC column
+type numeric struct {
+	precision int
+	scale     int
+}
+
+var (
+	priceColumn   = numeric{38, 18} // price
+	amountColumn  = numeric{38, 2}  // market caps, volumes, TVL
+	supplyColumn  = numeric{38, 8}  // supplies
+	percentColumn = numeric{20, 4}  // percent changes
+	ratioColumn   = numeric{20, 8}  // tvl_ratio
+	shareColumn   = numeric{10, 4}  // market_cap_dominance
+)
+
+// check returns an error naming the field if the value doesn't fit the column.
+func (n numeric) check(field string, d decimal.Decimal) error {
+	if err := d.Fits(n.precision, n.scale); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+// checkOptional checks a nullable value.
+func (n numeric) checkOptional(field string, d *decimal.Decimal) error {
+	if d == nil {
+		return nil
+	}
+	return n.check(field, *d)
+}
+
+// checkFloat checks a float value (percent changes, ratios).
+func (n numeric) checkFloat(field string, f *float64) error {
+	if f == nil {
+		return nil
+	}
+	return n.check(field, decimal.FromFloat(*f))
+}
+
+// validateCoinInfo returns the coin_info fields that don't fit their columns.
+func validateCoinInfo(coin CoinInfo) error {
+	return errors.Join(
+		supplyColumn.check("circulating_supply", coin.CirculatingSupply),
+		supplyColumn.check("total_supply", coin.TotalSupply),
+		supplyColumn.checkOptional("max_supply", coin.MaxSupply),
+		supplyColumn.checkOptional("self_reported_circulating_supply", coin.SelfReportedCirculatingSupply),
+		amountColumn.checkOptional("self_reported_market_cap", coin.SelfReportedMarketCap),
+		ratioColumn.checkFloat("tvl_ratio", coin.TvlRatio),
+	)
+}
+
+// validateQuote returns the coin_quote fields that don't fit their columns.
+func validateQuote(quote CoinQuote) error {
+	return errors.Join(
+		priceColumn.check("price", quote.Price),
+		amountColumn.check("market_cap", quote.MarketCap),
+		amountColumn.check("fully_diluted_market_cap", quote.FullyDilutedMarketCap),
+		amountColumn.check("volume_24h", quote.Volume24H),
+		amountColumn.checkOptional("volume_24h_reported", quote.Volume24HReported),
+		amountColumn.checkOptional("tvl", quote.Tvl),
+		percentColumn.checkFloat("percent_change_1h", &quote.PercentChange1H),
+		percentColumn.checkFloat("percent_change_24h", &quote.PercentChange24h),
+		percentColumn.checkFloat("percent_change_7d", &quote.PercentChange7d),
+		percentColumn.checkFloat("percent_change_30d", quote.PercentChange30d),
+		percentColumn.checkFloat("percent_change_60d", quote.PercentChange60d),
+		percentColumn.checkFloat("percent_change_90d", quote.PercentChange90d),
+		percentColumn.checkFloat("volume_change_24h", quote.VolumeChange24H),
+		shareColumn.checkFloat("market_cap_dominance", quote.MarketCapDominance),
+	)
+}
+
+// validateDisagreement returns the quote_disagreement prices that don't fit their columns.
+func validateDisagreement(d Disagreement) error {
+	return errors.Join(
+		priceColumn.check("primary_price", d.PrimaryPrice),
+		priceColumn.check("secondary_price", d.SecondaryPrice),
+		priceColumn.check("stored_price", d.Stored),
+	)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"os"
 )
 
@@ -15,3 +16,20 @@ func WriteJSONToFile(respBody []byte, name string) error {
 	}
 	return nil
 }
+
+// AppendJSONLine marshals v and appends it as one line to the file at path (NDJSON), creating the file if needed.
+func AppendJSONLine(path string, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}